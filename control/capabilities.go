@@ -0,0 +1,37 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+// lastCompatibility remembers the last result per display, so that a mismatch is only logged once.
+var lastCompatibility = map[string]api.Compatibility{}
+var lastCompatibilityMutex sync.Mutex
+
+func getDisplayCapabilities(ip string) (api.CapabilitiesResponse, error) {
+	display := newDisplayClient(ip)
+	display.HTTPClient.Timeout = 5 * time.Second
+
+	capabilities, err := display.Capabilities()
+	if err != nil {
+		return capabilities, err
+	}
+
+	compatibility := api.CheckCompatibility(capabilities)
+
+	lastCompatibilityMutex.Lock()
+	defer lastCompatibilityMutex.Unlock()
+	if lastCompatibility[ip] != compatibility && compatibility != api.Compatible {
+		slog.Warn("Display API version does not match", "ip", ip, "compatibility", compatibility,
+			"displayVersion", capabilities.Version, "displayAPIVersion", capabilities.APIVersion,
+			"controlVersion", shared.Version, "controlAPIVersion", api.APIVersion)
+	}
+	lastCompatibility[ip] = compatibility
+
+	return capabilities, nil
+}
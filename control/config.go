@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"plg-mudics/shared/api"
+	"plg-mudics/shared/client"
+)
+
+var Config = ConfigType{
+	Listen:          ":8080",
+	LogLevel:        "info",
+	BrowserBinaries: []string{"chromium", "chromium-browser"},
+	DisplayScheme:   "http",
+	DisplayPort:     api.DisplayPort,
+}
+
+type ConfigType struct {
+	Listen               string   `json:"listen" flag:"listen" env:"PLG_MUDICS_LISTEN" usage:"address the web interface and API listen on"`
+	StorageDir           string   `json:"storageDir" flag:"storage-dir" env:"PLG_MUDICS_STORAGE_DIR" usage:"directory for storage.json and update binaries (default ~/.local/share/plg-mudics/control)"`
+	BrowserBinaries      []string `json:"browserBinaries" flag:"browser" env:"PLG_MUDICS_BROWSER" usage:"chromium binaries to try for the app window, in order"`
+	BrowserFlags         []string `json:"browserFlags" flag:"browser-flags" env:"PLG_MUDICS_BROWSER_FLAGS" usage:"additional chromium flags for the app window"`
+	BrowserDataDir       string   `json:"browserDataDir" flag:"browser-data-dir" env:"PLG_MUDICS_BROWSER_DATA_DIR" usage:"chromium profile directory (default ~/.local/share/plg-mudics/browser-control)"`
+	Headless             bool     `json:"headless" flag:"headless" env:"PLG_MUDICS_HEADLESS" usage:"only run the server, do not open the app window"`
+	LogLevel             string   `json:"logLevel" flag:"log-level" env:"PLG_MUDICS_LOG_LEVEL" usage:"debug, info, warn or error"`
+	DisplayScheme        string   `json:"displayScheme" flag:"display-scheme" env:"PLG_MUDICS_DISPLAY_SCHEME" usage:"URL scheme of the display API"`
+	DisplayPort          int      `json:"displayPort" flag:"display-port" env:"PLG_MUDICS_DISPLAY_PORT" usage:"port of the display API"`
+	UpdateToken          string   `json:"updateToken" env:"PLG_MUDICS_UPDATE_TOKEN" usage:"shared secret that authorizes pushed updates on the displays, also needed as bearer token to upload and roll out binaries"`
+	UpdatePublicKey      string   `json:"updatePublicKey" env:"PLG_MUDICS_UPDATE_PUBLIC_KEY" usage:"base64 ed25519 public key the signatures of uploaded binaries are checked with"`
+	AllowUnsignedUpdates bool     `json:"allowUnsignedUpdates" flag:"allow-unsigned-updates" env:"PLG_MUDICS_ALLOW_UNSIGNED_UPDATES" usage:"roll out binaries without a signature"`
+}
+
+func newDisplayClient(ip string) *client.Display {
+	display := client.NewDisplay(ip)
+	display.BaseURL = fmt.Sprintf("%s://%s", Config.DisplayScheme, net.JoinHostPort(ip, strconv.Itoa(Config.DisplayPort)))
+	return display
+}
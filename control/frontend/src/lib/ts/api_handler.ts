@@ -1,8 +1,11 @@
 import { notifications } from './stores/notification';
 import {
 	is_folder,
+	type Capabilities,
+	type Compatibility,
 	to_display_status,
 	type DisplayStatus,
+	type ErrorResponse,
 	type Inode,
 	type RequestResponse,
 	type ShellCommandResponse,
@@ -72,7 +75,7 @@ export async function get_file_data(
 		size: string;
 		created: string;
 	}
-	const command = `cd ".${path}" && find . -maxdepth 1 -mindepth 1 -print0 | while IFS= read -r -d '' f; do
+	const command = `find . -maxdepth 1 -mindepth 1 -print0 | while IFS= read -r -d '' f; do
         typ=$(file -b --mime-type -- "$f")
         size=$(stat -c '%s' -- "$f")
         created=$(stat -c '%w' -- "$f")
@@ -82,11 +85,12 @@ export async function get_file_data(
         echo
         done
     `;
-	const raw_response = await run_shell_command(ip, command);
+	const raw_response = await run_shell_command(ip, command, path);
+	if (is_missing_folder(ip, raw_response)) return null;
 	if (!raw_response.ok || !raw_response.json) return null;
 	const json_response = raw_response.json as ShellCommandResponse;
 	if (json_response.exitCode === 0 && json_response.stdout.trim() === '') return [];
-	if (handle_shell_error(ip, json_response, command, true)) return null;
+	if (handle_shell_error(ip, json_response, command)) return null;
 	if (json_response.stdout.trim() === '') return null;
 
 	const response: FileInfo[] = json_response.stdout
@@ -114,12 +118,13 @@ export async function get_file_data(
 }
 
 export async function get_file_tree_data(ip: string, path: string): Promise<TreeElement[] | null> {
-	const command = `cd ".${path}" && tree -Js`;
-	const raw_response = await run_shell_command(ip, command);
+	const command = `tree -Js`;
+	const raw_response = await run_shell_command(ip, command, path);
 
+	if (is_missing_folder(ip, raw_response)) return null;
 	if (!raw_response.ok || !raw_response.json) return null;
 	const json_response = raw_response.json as ShellCommandResponse;
-	if (handle_shell_error(ip, json_response, command, true)) return null;
+	if (handle_shell_error(ip, json_response, command)) return null;
 
 	const tree_element: TreeElement | null = JSON.parse(json_response.stdout.trim())[0] || null;
 
@@ -132,7 +137,7 @@ export async function create_path(ip: string, path: string): Promise<void> {
 	const raw_response = await run_shell_command(ip, command);
 	if (!raw_response.ok || !raw_response.json) return;
 	const json_response = raw_response.json as ShellCommandResponse;
-	handle_shell_error(ip, json_response, command, true);
+	handle_shell_error(ip, json_response, command);
 }
 
 export async function rename_file(
@@ -141,12 +146,13 @@ export async function rename_file(
 	old_file_name: string,
 	new_file_name: string
 ): Promise<void> {
-	const command: string = `cd ".${path}" && mv "${old_file_name}" "${new_file_name}"`;
+	const command: string = `mv "${old_file_name}" "${new_file_name}"`;
 
-	const raw_response = await run_shell_command(ip, command);
+	const raw_response = await run_shell_command(ip, command, path);
+	if (is_missing_folder(ip, raw_response)) return;
 	if (!raw_response.ok || !raw_response.json) return;
 	const json_response = raw_response.json as ShellCommandResponse;
-	handle_shell_error(ip, json_response, command, true);
+	handle_shell_error(ip, json_response, command);
 }
 
 export async function delete_files(
@@ -154,14 +160,12 @@ export async function delete_files(
 	current_path: string,
 	file_names: string[]
 ): Promise<void> {
-	let command: string = `cd ".${current_path}"`;
-	for (const file_name of file_names) {
-		command += ` && rm -r "${file_name}"`;
-	}
-	const raw_response = await run_shell_command(ip, command);
+	const command: string = file_names.map((file_name) => `rm -r "${file_name}"`).join(' && ');
+	const raw_response = await run_shell_command(ip, command, current_path);
+	if (is_missing_folder(ip, raw_response)) return;
 	if (!raw_response.ok || !raw_response.json) return;
 	const json_response = raw_response.json as ShellCommandResponse;
-	handle_shell_error(ip, json_response, command, true);
+	handle_shell_error(ip, json_response, command);
 }
 
 export async function show_blackscreen(ip: string): Promise<void> {
@@ -186,10 +190,49 @@ export async function get_thumbnail_blob(ip: string, path_to_file: string): Prom
 	return raw_response.blob;
 }
 
+const display_capabilities = new Map<string, Capabilities>();
+const display_compatibility = new Map<string, Compatibility>();
+
+export function supports_feature(ip: string, feature: string): boolean {
+	const capabilities = display_capabilities.get(ip);
+	if (!capabilities) return true; // unknown -> just try it
+	return capabilities.features.includes(feature);
+}
+
+function required_feature(api_route: string, method: string): string | null {
+	if (api_route.startsWith('/file/preview/')) return 'file.preview';
+	if (api_route.startsWith('/file/')) return method === 'PATCH' ? 'file.open' : 'file.transfer';
+	const route_features: Record<string, string> = {
+		'/shellCommand': 'shellCommand',
+		'/keyboardInput': 'keyboardInput',
+		'/showHTML': 'showHTML',
+		'/takeScreenshot': 'takeScreenshot',
+		'/openWebsite': 'openWebsite'
+	};
+	return route_features[api_route] ?? null;
+}
+
 export async function ping_ip(ip: string): Promise<DisplayStatus> {
 	const raw_response = await request_control(`/ping?ip=${ip}`, { method: 'GET' });
 	if (!raw_response.ok || !raw_response.json) return null;
 
+	const capabilities = raw_response.json.capabilities as Capabilities | undefined;
+	const compatibility = raw_response.json.compatibility as Compatibility | undefined;
+	if (capabilities && compatibility) {
+		display_capabilities.set(ip, capabilities);
+		if (compatibility !== 'compatible' && display_compatibility.get(ip) !== compatibility) {
+			notifications.push(
+				'info',
+				'Versionskonflikt',
+				`${ip} nutzt Version ${capabilities.version}` +
+					(compatibility === 'display_outdated'
+						? ' und sollte aktualisiert werden.'
+						: ' und ist neuer als die Steuerung.')
+			);
+		}
+		display_compatibility.set(ip, compatibility);
+	}
+
 	const status = raw_response.json.status;
 	if (typeof status === 'string') {
 		return to_display_status(status);
@@ -208,6 +251,14 @@ async function request_display(
 	const current_online_displays = get(online_displays);
 	if (!current_online_displays.map((d) => d.ip).includes(ip)) return { ok: false };
 
+	const feature = required_feature(api_route, options.method);
+	if (feature !== null && !supports_feature(ip, feature)) {
+		if (dev) {
+			console.debug(`display ${ip} does not support ${feature}`);
+		}
+		return { ok: false };
+	}
+
 	const response = await request(url, options, supress_error_handling_http_codes);
 	if (!response.ok && response.http_code === 408) {
 		// Network error -> device possibly not longer online -> test status and throw no error if its offline
@@ -255,8 +306,8 @@ async function request(
 		let error_description = url;
 		if (response.headers.get('content-type')?.includes('application/json')) {
 			try {
-				const json: { description: string } = await response.json();
-				error_description += '\n' + json.description;
+				const json: ErrorResponse = await response.json();
+				error_description += `\n${json.description} (${json.code})`;
 			} catch {
 				error_description += '\nCould not parse error description';
 			}
@@ -280,23 +331,20 @@ async function request(
 	return { ok: false };
 }
 
+function is_missing_folder(ip: string, response: RequestResponse): boolean {
+	if (response.http_code !== 404 || response.json?.code !== 'file_not_found') return false;
+	if (dev) {
+		console.debug('current file_path does not exist on display:', ip);
+	}
+	return true;
+}
+
 function handle_shell_error(
 	ip: string,
 	shell_response: ShellCommandResponse,
-	shell_command: string,
-	command_includs_cd: boolean
+	shell_command: string
 ): boolean {
 	if (shell_response.exitCode !== 0) {
-		if (
-			command_includs_cd &&
-			shell_response.stderr &&
-			/bash: line \d+: cd: .+: No such file or directory/.test(shell_response.stderr)
-		) {
-			if (dev) {
-				console.debug('current file_path does not exist on display:', ip);
-			}
-			return true;
-		}
 		console.error(shell_response);
 		notifications.push(
 			'error',
@@ -308,15 +356,23 @@ function handle_shell_error(
 	return false;
 }
 
-async function run_shell_command(ip: string, command: string): Promise<RequestResponse> {
+async function run_shell_command(
+	ip: string,
+	command: string,
+	dir: string = '/'
+): Promise<RequestResponse> {
+	// displays without shellCommand.dir ignore dir and would run the command in the storage root
+	const supports_dir =
+		display_capabilities.get(ip)?.features.includes('shellCommand.dir') ?? false;
+	if (!supports_dir && dir !== '/') {
+		command = `cd ".${dir}" && ${command}`;
+	}
 	const options = {
 		method: 'PATCH',
 		headers: { 'content-type': 'application/json' },
-		body: JSON.stringify({
-			command: command
-		})
+		body: JSON.stringify(supports_dir ? { command: command, dir: dir } : { command: command })
 	};
-	return await request_display(ip, '/shellCommand', options);
+	return await request_display(ip, '/shellCommand', options, [404]);
 }
 
 export async function shutdown(ip: string): Promise<RequestResponse> {
@@ -1,4 +1,14 @@
-import { FileBox, FileImage, FileText, FileVideoCamera, ImagePlay, type X } from 'lucide-svelte';
+import {
+	FileArchive,
+	FileBox,
+	FileImage,
+	FileMusic,
+	FileText,
+	FileType,
+	FileVideoCamera,
+	ImagePlay,
+	type X
+} from 'lucide-svelte';
 import type { Snippet } from 'svelte';
 
 export type RequestResponse = {
@@ -8,6 +18,21 @@ export type RequestResponse = {
 	json?: Record<string, unknown>;
 };
 
+export type ErrorResponse = {
+	code: string;
+	description: string;
+	details?: Record<string, string>;
+};
+
+export type Capabilities = {
+	version: string;
+	apiVersion: number;
+	features: string[];
+	tools?: Record<string, boolean>;
+};
+
+export type Compatibility = 'compatible' | 'display_outdated' | 'display_newer';
+
 export type ShellCommandResponse = {
 	stdout: string;
 	stderr: string;
@@ -25,7 +50,22 @@ export const supported_file_type_icon: Record<string, typeof X> = {
 	GIF: ImagePlay,
 	PPTX: FileBox,
 	ODP: FileBox,
-	PDF: FileText
+	PDF: FileText,
+	WEBM: FileVideoCamera,
+	OGV: FileVideoCamera,
+	WEBP: FileImage,
+	AVIF: FileImage,
+	SVG: FileImage,
+	MP3: FileMusic,
+	OGG: FileMusic,
+	WAV: FileMusic,
+	FLAC: FileMusic,
+	M4A: FileMusic,
+	TXT: FileType,
+	MD: FileType,
+	DOCX: FileText,
+	ODT: FileText,
+	ZIP: FileArchive
 };
 
 export type FileTransferTask = {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log/slog"
 	"net"
 	"net/http"
@@ -9,6 +11,9 @@ import (
 	"path/filepath"
 	"plg-mudics/control/frontend"
 	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+	"plg-mudics/shared/config"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -21,6 +26,20 @@ var storageFile string
 func main() {
 	var err error
 
+	err = config.Load(&Config, "control", os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(2)
+	}
+	err = config.SetLogLevel(Config.LogLevel)
+	if err != nil {
+		slog.Error("Failed to set log level", "error", err)
+		os.Exit(2)
+	}
+
 	path, err := getStoragePath()
 	if err != nil {
 		slog.Error("Failed to initialize storage path", "error", err)
@@ -47,18 +66,34 @@ func main() {
 	}))
 
 	// Servers all API endpoints, e.g. our custom logic
-	apiGroup := e.Group("/api")
-	apiGroup.GET("/ping", pingRoute)
-	apiGroup.POST("/wakeOnLan", wakeOnLanRoute)
-	apiGroup.GET("/storage", getStorageRoute)
-	apiGroup.POST("/storage", setStorageRoute)
+	apiGroup := e.Group(api.Prefix)
+	apiGroup.GET(api.PathHostPing, pingRoute)
+	apiGroup.GET(api.PathOpenAPI, openAPIRoute)
+	apiGroup.POST(api.PathWakeOnLan, wakeOnLanRoute)
+	apiGroup.GET(api.PathStorage, getStorageRoute)
+	apiGroup.POST(api.PathStorage, setStorageRoute)
+	apiGroup.PUT(api.PathUpdateBinary, uploadUpdateBinaryRoute, updateAuthMiddleware)
+	apiGroup.GET(api.PathUpdateBinary, getUpdateBinaryRoute)
+	apiGroup.POST(api.PathUpdateRollout, startRolloutRoute, updateAuthMiddleware)
+	apiGroup.GET(api.PathUpdateRollout, getRolloutRoute)
+	apiGroup.POST(api.PathThemeApply, applyThemeRoute)
+
+	if Config.Headless {
+		err = e.Start(Config.Listen)
+		slog.Error("Failed to start Echo Webserver", "error", err)
+		os.Exit(1)
+	}
 
-	port := "8080"
+	_, port, err := net.SplitHostPort(Config.Listen)
+	if err != nil {
+		slog.Error("Invalid listen address", "address", Config.Listen, "error", err)
+		os.Exit(2)
+	}
 
 	// the order is important, the open browser command exitsts as soon as the winodw is closed
 	// and since its the last action in the main go func all other goroutines (e.g. the webserver) are killed
 	go func() {
-		err := e.Start(":" + port)
+		err := e.Start(Config.Listen)
 		if err != nil {
 			slog.Error("Failed to start Echo Webserver", "error", err)
 			os.Exit(1)
@@ -74,53 +109,59 @@ func main() {
 func pingRoute(ctx echo.Context) error {
 	ip := ctx.QueryParam("ip")
 	if ip == "" {
-		return ctx.JSON(http.StatusBadRequest, PingResponse{Error: "missing 'ip' query parameter"})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Missing 'ip' query parameter"})
 	}
 
 	cmd := exec.Command("ping", "-c", "1", "-w", "5", ip)
 	result := shared.RunShellCommand(cmd)
 	if result.ExitCode != 0 {
-		return ctx.JSON(http.StatusOK, PingResponse{Status: "host_offline"})
+		return ctx.JSON(http.StatusOK, api.HostPingResponse{Status: api.HostOffline})
 	}
 
-	conn, err := net.DialTimeout("tcp", ip+":1323", 5*time.Second)
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(Config.DisplayPort)), 5*time.Second)
 	if err != nil {
-		return ctx.JSON(http.StatusOK, PingResponse{Status: "app_offline"})
+		return ctx.JSON(http.StatusOK, api.HostPingResponse{Status: api.AppOffline})
 	}
 	conn.Close()
 
-	return ctx.JSON(http.StatusOK, PingResponse{Status: "app_online"})
-}
-
-type PingResponse struct {
-	Status string `json:"status"`
-	Error  string `json:"error"`
-}
+	response := api.HostPingResponse{Status: api.AppOnline}
+	capabilities, err := getDisplayCapabilities(ip)
+	if err != nil {
+		slog.Warn("Failed to get display capabilities", "ip", ip, "error", err)
+		return ctx.JSON(http.StatusOK, response)
+	}
+	response.Capabilities = &capabilities
+	response.Compatibility = api.CheckCompatibility(capabilities)
 
-type WakeOnLanRequest struct {
-	MACAddress string `json:"mac_address"`
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func wakeOnLanRoute(ctx echo.Context) error {
-	var data WakeOnLanRequest
+	var data api.WakeOnLanRequest
 	if err := ctx.Bind(&data); err != nil {
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: shared.BadRequestDescription})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
 	}
 	mac, err := net.ParseMAC(data.MACAddress)
 	if err != nil {
 		slog.Warn("Invalid MAC address provided", "mac_address", data.MACAddress, "error", err)
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: "Invalid MAC address"})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid MAC address", Details: map[string]string{"mac_address": data.MACAddress}})
 	}
 
 	client, err := wol.NewClient()
 	if err != nil {
 		slog.Error("Failed to create Wake-on-LAN client", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to create Wake-on-LAN client"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to create Wake-on-LAN client"})
 	}
 	if err := client.Wake("255.255.255.255:7", mac); err != nil {
 		slog.Error("Failed to send Wake-on-LAN packet", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to send Wake-on-LAN packet"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to send Wake-on-LAN packet"})
 	}
 
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+var openAPIDocument = api.OpenAPI("PLG MuDiCS Control", api.ControlRoutes)
+
+func openAPIRoute(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, openAPIDocument)
 }
@@ -7,16 +7,20 @@ import (
 	"os/exec"
 	"path/filepath"
 	"plg-mudics/shared"
+	"strings"
 )
 
 func openBrowserWindow(url string) error {
-	bins := []string{"chromium", "chromium-browser"}
+	bins := Config.BrowserBinaries
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("unable to determine user home directory: %w", err)
+	browserProfileDirPath := Config.BrowserDataDir
+	if browserProfileDirPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("unable to determine user home directory: %w", err)
+		}
+		browserProfileDirPath = filepath.Join(home, ".local", "share", "plg-mudics", "browser-control")
 	}
-	browserProfileDirPath := filepath.Join(home, ".local", "share", "plg-mudics", "browser-control")
 	if err := os.MkdirAll(browserProfileDirPath, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create local config directory: %w", err)
 	}
@@ -25,6 +29,9 @@ func openBrowserWindow(url string) error {
 		fmt.Sprintf("--app=%s", url),
 		fmt.Sprintf("--user-data-dir=%s", browserProfileDirPath),
 	}
+	for _, flag := range Config.BrowserFlags {
+		args = append(args, "--"+strings.TrimPrefix(flag, "--"))
+	}
 
 	errs := []string{}
 	for _, bin := range bins {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"plg-mudics/shared"
+	"plg-mudics/shared/api"
 
 	"github.com/labstack/echo/v4"
 )
@@ -16,13 +17,13 @@ func getStorageRoute(ctx echo.Context) error {
 	data, err := os.ReadFile(storageFile)
 	if err != nil {
 		slog.Error("Could not read storage file", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Could not read storage file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Could not read storage file"})
 	}
 
 	var content interface{}
 	if err := json.Unmarshal(data, &content); err != nil {
 		slog.Error("Could not parse storage file", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Could not parse storage file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Could not parse storage file"})
 	}
 
 	return ctx.JSON(http.StatusOK, content)
@@ -31,28 +32,30 @@ func getStorageRoute(ctx echo.Context) error {
 func setStorageRoute(ctx echo.Context) error {
 	var payload interface{}
 	if err := ctx.Bind(&payload); err != nil {
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: "Invalid JSON payload"})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid JSON payload"})
 	}
 
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to marshal storage file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to marshal storage file"})
 	}
 	if err := os.WriteFile(storageFile, data, 0644); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to write storage file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to write storage file"})
 	}
 
-	return ctx.JSON(http.StatusOK, map[string]interface{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
 
 func getStoragePath() (string, error) {
-	var storagePath string
+	storagePath := Config.StorageDir
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("unable to determine user home directory: %w", err)
+	if storagePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to determine user home directory: %w", err)
+		}
+		storagePath = filepath.Join(home, ".local", "share", "plg-mudics", "control")
 	}
-	storagePath = filepath.Join(home, ".local", "share", "plg-mudics", "control")
 	if err := os.MkdirAll(storagePath, os.ModePerm); err != nil {
 		return "", fmt.Errorf("failed to create local config directory: %w", err)
 	}
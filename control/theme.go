@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+	"plg-mudics/shared/client"
+)
+
+func applyThemeRoute(ctx echo.Context) error {
+	var request api.ThemeApplyRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	response := api.ThemeApplyResponse{Results: make([]api.ThemeApplyResult, len(request.IPs))}
+	var wg sync.WaitGroup
+	for i, ip := range request.IPs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response.Results[i].IP = ip
+			if err := applyTheme(ip, request.Theme); err != nil {
+				slog.Error("Failed to apply theme", "ip", ip, "theme", request.Theme.Name, "error", err)
+				response.Results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	slog.Info("Theme applied", "theme", request.Theme.Name, "displays", len(request.IPs))
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// applyTheme stores the theme on a display and activates it. The logo and background image
+// are not copied, they have to be uploaded to the display before.
+func applyTheme(ip string, theme api.Theme) error {
+	display := newDisplayClient(ip)
+
+	capabilities, err := getDisplayCapabilities(ip)
+	if err != nil {
+		return fmt.Errorf("display not reachable: %w", err)
+	}
+	if !capabilities.Supports(api.FeatureTheme) {
+		return fmt.Errorf("display %s does not support themes", capabilities.Version)
+	}
+
+	// the control server has no files, the images have to be on every display already
+	for name, path := range map[string]string{"logo": theme.Logo, "background image": theme.BackgroundImage} {
+		if path == "" {
+			continue
+		}
+		if _, err := display.FileMeta(path); client.IsCode(err, shared.CodeFileNotFound) {
+			return fmt.Errorf("%s %s is missing on the display, upload it there first", name, path)
+		} else if err != nil {
+			return fmt.Errorf("failed to check %s %s: %w", name, path, err)
+		}
+	}
+
+	if _, err := display.SetTheme(theme); err != nil {
+		return err
+	}
+	return display.ActivateTheme(theme.Name)
+}
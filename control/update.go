@@ -0,0 +1,393 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+	"plg-mudics/shared/client"
+)
+
+const healthCheckTimeout = 90 * time.Second
+const healthCheckInterval = 2 * time.Second
+
+var rollout rolloutType
+
+type rolloutType struct {
+	mutex  sync.Mutex
+	status api.RolloutStatusResponse
+}
+
+func getUpdateBinaryPaths() (string, string, error) {
+	path, err := getStoragePath()
+	if err != nil {
+		return "", "", err
+	}
+	dir := filepath.Join(path, "display-update")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", "", fmt.Errorf("failed to create update directory: %w", err)
+	}
+	return filepath.Join(dir, "plg-mudics-display"), filepath.Join(dir, "binary.json"), nil
+}
+
+func readUpdateBinaryInfo() (api.UpdateBinaryResponse, error) {
+	var info api.UpdateBinaryResponse
+
+	_, infoPath, err := getUpdateBinaryPaths()
+	if err != nil {
+		return info, err
+	}
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+// updateAuthMiddleware only lets requests with Config.UpdateToken as bearer token change the
+// binary of the displays, the same token the displays check.
+func updateAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		token := Config.UpdateToken
+		if token == "" {
+			return ctx.JSON(http.StatusForbidden, shared.ErrorResponse{Code: shared.CodeUnauthorized, Description: "Updates are disabled, no update token is configured"})
+		}
+
+		given, ok := strings.CutPrefix(ctx.Request().Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			slog.Warn("Rejected update request with wrong token", "remote", ctx.RealIP())
+			return ctx.JSON(http.StatusUnauthorized, shared.ErrorResponse{Code: shared.CodeUnauthorized, Description: "Invalid update token"})
+		}
+
+		return next(ctx)
+	}
+}
+
+// verifyUpdateSignature checks the signature of digest with Config.UpdatePublicKey, like the
+// displays do. Unsigned binaries are only accepted with Config.AllowUnsignedUpdates.
+func verifyUpdateSignature(digest []byte, signature string) error {
+	if signature == "" {
+		if Config.AllowUnsignedUpdates {
+			return nil
+		}
+		return errors.New("the binary is unsigned, unsigned binaries are only accepted with allowUnsignedUpdates")
+	}
+	if Config.UpdatePublicKey == "" {
+		return errors.New("no updatePublicKey is configured to check the signature")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(Config.UpdatePublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("the configured updatePublicKey is invalid")
+	}
+	rawSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(key, digest, rawSignature) {
+		return errors.New("the signature does not match the binary")
+	}
+	return nil
+}
+
+// verifyStoredUpdateBinary checks the stored binary against its checksum and signature.
+func verifyStoredUpdateBinary(info api.UpdateBinaryResponse) error {
+	binaryPath, _, err := getUpdateBinaryPaths()
+	if err != nil {
+		return err
+	}
+	binary, err := os.Open(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to open update binary: %w", err)
+	}
+	defer binary.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, binary); err != nil {
+		return fmt.Errorf("failed to read update binary: %w", err)
+	}
+	digest := hash.Sum(nil)
+	if hex.EncodeToString(digest) != info.SHA256 {
+		return errors.New("the stored binary does not match its checksum")
+	}
+	return verifyUpdateSignature(digest, info.Signature)
+}
+
+func uploadUpdateBinaryRoute(ctx echo.Context) error {
+	info := api.UpdateBinaryResponse{
+		Version:   ctx.Request().Header.Get(api.HeaderUpdateVersion),
+		SHA256:    ctx.Request().Header.Get(api.HeaderUpdateSHA256),
+		Signature: ctx.Request().Header.Get(api.HeaderUpdateSignature),
+	}
+	if info.Version == "" || info.SHA256 == "" {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Missing " + api.HeaderUpdateVersion + " or " + api.HeaderUpdateSHA256 + " header"})
+	}
+	rollout.mutex.Lock()
+	defer rollout.mutex.Unlock()
+	if rollout.status.Running {
+		return ctx.JSON(http.StatusConflict, shared.ErrorResponse{Code: shared.CodeBusy, Description: "A rollout is running"})
+	}
+
+	binaryPath, infoPath, err := getUpdateBinaryPaths()
+	if err != nil {
+		slog.Error("Failed to get update binary path", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to prepare update directory"})
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(binaryPath), ".upload-*")
+	if err != nil {
+		slog.Error("Failed to create temporary file", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to create file"})
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	hash := sha256.New()
+	info.Size, err = io.Copy(io.MultiWriter(tempFile, hash), ctx.Request().Body)
+	if err != nil {
+		slog.Error("Failed to write update binary", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to write file"})
+	}
+	digest := hash.Sum(nil)
+	if hex.EncodeToString(digest) != info.SHA256 {
+		return ctx.JSON(http.StatusUnprocessableEntity, shared.ErrorResponse{Code: shared.CodeChecksumMismatch, Description: "Checksum does not match"})
+	}
+	if err := verifyUpdateSignature(digest, info.Signature); err != nil {
+		slog.Warn("Rejected update binary", "version", info.Version, "error", err)
+		return ctx.JSON(http.StatusUnprocessableEntity, shared.ErrorResponse{Code: shared.CodeSignatureInvalid, Description: err.Error()})
+	}
+
+	if err := os.Rename(tempFile.Name(), binaryPath); err != nil {
+		slog.Error("Failed to store update binary", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to save file"})
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err == nil {
+		err = os.WriteFile(infoPath, data, 0644)
+	}
+	if err != nil {
+		slog.Error("Failed to store update binary info", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to save file"})
+	}
+
+	slog.Info("Display update binary stored", "version", info.Version, "sha256", info.SHA256)
+	return ctx.JSON(http.StatusOK, info)
+}
+
+func getUpdateBinaryRoute(ctx echo.Context) error {
+	info, err := readUpdateBinaryInfo()
+	if errors.Is(err, os.ErrNotExist) {
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "No update binary uploaded"})
+	}
+	if err != nil {
+		slog.Error("Failed to read update binary info", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read update binary info"})
+	}
+
+	return ctx.JSON(http.StatusOK, info)
+}
+
+func startRolloutRoute(ctx echo.Context) error {
+	var request api.RolloutRequest
+	if err := ctx.Bind(&request); err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	info, err := readUpdateBinaryInfo()
+	if errors.Is(err, os.ErrNotExist) {
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "No update binary uploaded"})
+	}
+	if err != nil {
+		slog.Error("Failed to read update binary info", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read update binary info"})
+	}
+
+	// the binary may have been stored with another key or while unsigned binaries were allowed
+	if err := verifyStoredUpdateBinary(info); err != nil {
+		slog.Warn("Refused to roll out update binary", "version", info.Version, "error", err)
+		return ctx.JSON(http.StatusUnprocessableEntity, shared.ErrorResponse{Code: shared.CodeSignatureInvalid, Description: err.Error()})
+	}
+
+	if !rollout.start(request.Groups, info) {
+		return ctx.JSON(http.StatusConflict, shared.ErrorResponse{Code: shared.CodeBusy, Description: "A rollout is already running"})
+	}
+
+	return ctx.JSON(http.StatusOK, rollout.getStatus())
+}
+
+func getRolloutRoute(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, rollout.getStatus())
+}
+
+func (r *rolloutType) start(groups [][]string, info api.UpdateBinaryResponse) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.status.Running {
+		return false
+	}
+
+	r.status = api.RolloutStatusResponse{Running: true, Version: info.Version, Displays: []api.RolloutDisplayStatus{}}
+	for i, group := range groups {
+		for _, ip := range group {
+			r.status.Displays = append(r.status.Displays, api.RolloutDisplayStatus{IP: ip, Group: i, State: api.RolloutPending})
+		}
+	}
+
+	go r.run(groups, info)
+	return true
+}
+
+func (r *rolloutType) getStatus() api.RolloutStatusResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := r.status
+	status.Displays = append([]api.RolloutDisplayStatus{}, r.status.Displays...)
+	return status
+}
+
+func (r *rolloutType) setState(group int, ip string, state api.RolloutState, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.status.Displays {
+		display := &r.status.Displays[i]
+		if display.Group == group && display.IP == ip {
+			display.State = state
+			display.Error = ""
+			if err != nil {
+				display.Error = err.Error()
+			}
+		}
+	}
+}
+
+func (r *rolloutType) run(groups [][]string, info api.UpdateBinaryResponse) {
+	defer func() {
+		r.mutex.Lock()
+		r.status.Running = false
+		r.mutex.Unlock()
+	}()
+
+	slog.Info("Starting display rollout", "version", info.Version, "groups", len(groups))
+
+	for i, group := range groups {
+		var wg sync.WaitGroup
+		pushed := make([]bool, len(group))
+		errs := make([]error, len(group))
+
+		for j, ip := range group {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.setState(i, ip, api.RolloutUpdating, nil)
+				pushed[j], errs[j] = updateDisplay(ip, info)
+				if errs[j] != nil {
+					r.setState(i, ip, api.RolloutFailed, errs[j])
+				} else {
+					r.setState(i, ip, api.RolloutUpdated, nil)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if errors.Join(errs...) == nil {
+			continue
+		}
+
+		slog.Error("Rollout failed, rolling back group", "group", i, "error", errors.Join(errs...))
+		for j, ip := range group {
+			if !pushed[j] {
+				continue
+			}
+			display := newUpdateClient(ip)
+			if err := display.RollbackUpdate(); err != nil {
+				// an unconfirmed display rolls back by itself on its next start
+				slog.Error("Failed to roll back display", "ip", ip, "error", err)
+				continue
+			}
+			r.setState(i, ip, api.RolloutRolledBack, errs[j])
+		}
+		for k := i + 1; k < len(groups); k++ {
+			for _, ip := range groups[k] {
+				r.setState(k, ip, api.RolloutSkipped, nil)
+			}
+		}
+		return
+	}
+
+	slog.Info("Display rollout finished", "version", info.Version)
+}
+
+// updateDisplay pushes the binary to a display and waits until it is healthy again.
+// It reports whether the binary was replaced on the display.
+func updateDisplay(ip string, info api.UpdateBinaryResponse) (bool, error) {
+	display := newUpdateClient(ip)
+
+	capabilities, err := display.Capabilities()
+	if err != nil {
+		return false, fmt.Errorf("display not reachable: %w", err)
+	}
+	if capabilities.Version == info.Version {
+		return false, nil
+	}
+	if !capabilities.Supports(api.FeatureUpdate) {
+		return false, fmt.Errorf("display %s does not support push updates", capabilities.Version)
+	}
+
+	binaryPath, _, err := getUpdateBinaryPaths()
+	if err != nil {
+		return false, err
+	}
+	binary, err := os.Open(binaryPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open update binary: %w", err)
+	}
+	defer binary.Close()
+
+	// the upload may take longer than a usual request
+	uploader := *display
+	uploader.HTTPClient = &http.Client{}
+	if err := uploader.Update(binary, info.SHA256, info.Signature); err != nil {
+		return false, err
+	}
+
+	deadline := time.Now().Add(healthCheckTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(healthCheckInterval)
+
+		capabilities, err := display.Capabilities()
+		if err != nil || capabilities.Version != info.Version {
+			continue
+		}
+		if err := display.ConfirmUpdate(); err != nil {
+			return true, fmt.Errorf("failed to confirm update: %w", err)
+		}
+		slog.Info("Display updated", "ip", ip, "version", info.Version)
+		return true, nil
+	}
+
+	return true, fmt.Errorf("display did not come back with version %s within %s", info.Version, healthCheckTimeout)
+}
+
+func newUpdateClient(ip string) *client.Display {
+	display := newDisplayClient(ip)
+	display.HTTPClient.Timeout = 10 * time.Second
+	display.UpdateToken = Config.UpdateToken
+	return display
+}
@@ -2,10 +2,15 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 )
 
@@ -14,14 +19,36 @@ var Browser BrowserType = BrowserType{}
 type BrowserType struct {
 	Ctx    context.Context
 	Cancel context.CancelFunc
+
+	mutex      sync.Mutex
+	transition Transition
+	playerPath string
+	shownURL   string
+	// pageScriptID identifies the script of SetPageScript in chromium
+	pageScriptID page.ScriptIdentifier
+	// website is set while OpenPage shows a website
+	website *website
+	// zoomed is set while Zoom magnifies the page
+	zoomed bool
 }
 
-func (b *BrowserType) Init() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("unable to determine user home directory: %w", err)
+type Options struct {
+	// Binary is looked up by chromedp if empty.
+	Binary string
+	// Flags are additional chromium flags, either "name" or "name=value".
+	Flags   []string
+	DataDir string
+}
+
+func (b *BrowserType) Init(options Options) error {
+	browserProfileDirPath := options.DataDir
+	if browserProfileDirPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("unable to determine user home directory: %w", err)
+		}
+		browserProfileDirPath = filepath.Join(home, ".local", "share", "plg-mudics", "browser-display")
 	}
-	browserProfileDirPath := filepath.Join(home, ".local", "share", "plg-mudics", "browser-display")
 	if err := os.MkdirAll(browserProfileDirPath, os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create local config directory: %w", err)
 	}
@@ -35,6 +62,17 @@ func (b *BrowserType) Init() error {
 		chromedp.Flag("user-data-dir", browserProfileDirPath),
 		chromedp.Flag("autoplay-policy", "no-user-gesture-required"),
 	}
+	if options.Binary != "" {
+		opts = append(opts, chromedp.ExecPath(options.Binary))
+	}
+	for _, flag := range options.Flags {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		if hasValue {
+			opts = append(opts, chromedp.Flag(name, value))
+		} else {
+			opts = append(opts, chromedp.Flag(name, true))
+		}
+	}
 
 	initCtx, _ := chromedp.NewExecAllocator(context.Background(), opts...)
 	b.Ctx, b.Cancel = chromedp.NewContext(initCtx)
@@ -42,8 +80,32 @@ func (b *BrowserType) Init() error {
 	return nil
 }
 
-func (b *BrowserType) OpenPage(url string) {
-	chromedp.Run(b.Ctx, chromedp.Navigate(url))
+// Close shuts the browser down and waits until it has exited.
+func (b *BrowserType) Close() error {
+	if b.Ctx == nil {
+		return nil
+	}
+	return chromedp.Cancel(b.Ctx)
+}
+
+// ScreenResolution returns the size of the screen in physical pixels.
+func (b *BrowserType) ScreenResolution() (int, int, error) {
+	if b.Ctx == nil {
+		return 0, 0, errors.New("browser is not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(b.Ctx, 5*time.Second)
+	defer cancel()
+
+	var size []float64
+	err := chromedp.Run(ctx, chromedp.Evaluate(`[screen.width * devicePixelRatio, screen.height * devicePixelRatio]`, &size))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read screen size: %w", err)
+	}
+	if len(size) != 2 {
+		return 0, 0, fmt.Errorf("unexpected screen size %v", size)
+	}
+	return int(size[0]), int(size[1]), nil
 }
 
 // Yes, we need that trick with creating a temp file and not directly sending html since
@@ -62,11 +124,14 @@ func (b *BrowserType) OpenHTML(html string) error {
 		return fmt.Errorf("could not write to tempfile: %w", err)
 	}
 
-	chromedp.Run(b.Ctx, chromedp.Navigate("file://"+tempFile.Name()))
+	return b.showInPlayer("file://" + tempFile.Name())
+}
 
-	return nil
+func (b *BrowserType) OpenPDF(path string) error {
+	return b.showInPlayer("file://" + path + "#toolbar=0&view=Fit")
 }
 
-func (b *BrowserType) OpenPDF(path string) {
-	b.OpenPage("file://" + path + "#toolbar=0&view=Fit")
+// OpenLocalPage shows a page of a local server in the player.
+func (b *BrowserType) OpenLocalPage(url string) error {
+	return b.showInPlayer(url)
 }
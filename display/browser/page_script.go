@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// SetPageScript runs script in the current page and in every page loaded afterwards,
+// including websites, but not in the frames of the player. It replaces the previous script.
+func (b *BrowserType) SetPageScript(script string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	register := chromedp.ActionFunc(func(ctx context.Context) error {
+		if b.pageScriptID != "" {
+			if err := page.RemoveScriptToEvaluateOnNewDocument(b.pageScriptID).Do(ctx); err != nil {
+				return fmt.Errorf("failed to remove page script: %w", err)
+			}
+			b.pageScriptID = ""
+		}
+
+		id, err := page.AddScriptToEvaluateOnNewDocument(script).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to add page script: %w", err)
+		}
+		b.pageScriptID = id
+		return nil
+	})
+
+	return chromedp.Run(b.Ctx, register, chromedp.Evaluate(script, nil))
+}
+
+// RunScript runs script once in the current page. Other than the page script, it is gone
+// when other content is opened.
+func (b *BrowserType) RunScript(script string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(script, nil)); err != nil {
+		return fmt.Errorf("failed to run script: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Kinds of Transition.
+const (
+	TransitionCut       = "cut"
+	TransitionCrossfade = "crossfade"
+	// TransitionFade fades the old item out to black and the new one in.
+	TransitionFade = "fade"
+)
+
+// Transition is how the player switches from the shown item to the next one.
+type Transition struct {
+	Kind     string
+	Duration time.Duration
+}
+
+// playerHTML stays loaded while local content changes. Every item is loaded into the hidden
+// of two frames when it is requested and only shown once it is ready, so nothing flashes
+// while it loads. Items are not loaded before they are requested.
+const playerHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8"/>
+<title>PLG MuDiCS Display</title>
+<style>
+	html, body { margin: 0; width: 100vw; height: 100vh; overflow: hidden; background-color: black; cursor: none; }
+	iframe { position: fixed; inset: 0; width: 100vw; height: 100vh; border: 0; opacity: 0; z-index: 0; }
+</style>
+</head>
+<body>
+<iframe allow="autoplay; fullscreen"></iframe>
+<iframe allow="autoplay; fullscreen"></iframe>
+<script>
+	const frames = document.querySelectorAll('iframe');
+	// slow pages are shown anyway after this time
+	const loadTimeout = 5000;
+	// front is the visible frame, the other one loads the requested item
+	let front = 0;
+	let current = 0;
+	// running finishes the transition that is in progress
+	let running = null;
+	// latest is the frame of the last item, even if it still loads
+	let latest = frames[0];
+
+	function settle(previous, next) {
+		previous.getAnimations().forEach((animation) => animation.cancel());
+		next.getAnimations().forEach((animation) => animation.cancel());
+		previous.style.opacity = 0;
+		previous.style.zIndex = 0;
+		next.style.opacity = 1;
+		next.style.zIndex = 1;
+		// stops videos and audio of the old item
+		previous.onload = null;
+		previous.src = 'about:blank';
+	}
+
+	function reveal(previous, next, kind, duration) {
+		const finish = () => {
+			if (running === finish) {
+				running = null;
+				settle(previous, next);
+			}
+		};
+		running = finish;
+		front = 1 - front;
+		next.style.zIndex = 2;
+		next.contentWindow.focus();
+
+		switch (kind) {
+			case 'crossfade':
+				next.animate([{ opacity: 0 }, { opacity: 1 }], { duration: duration, fill: 'forwards' }).onfinish = finish;
+				break;
+			case 'fade':
+				previous.animate([{ opacity: 1 }, { opacity: 0 }], { duration: duration / 2, fill: 'forwards' }).onfinish = () => {
+					if (running === finish) {
+						next.animate([{ opacity: 0 }, { opacity: 1 }], { duration: duration / 2, fill: 'forwards' }).onfinish = finish;
+					}
+				};
+				break;
+			default:
+				finish();
+		}
+	}
+
+	window.plgMudicsPlayer = {
+		show(url, kind, duration) {
+			const id = ++current;
+			if (running) {
+				running();
+			}
+			const previous = frames[front];
+			const next = frames[1 - front];
+			latest = next;
+			next.style.opacity = 0;
+			next.style.zIndex = 0;
+
+			let shown = false;
+			const show = () => {
+				if (!shown && id === current) {
+					shown = true;
+					reveal(previous, next, kind, duration);
+				}
+			};
+			next.onload = show;
+			setTimeout(show, loadTimeout);
+			next.src = url;
+		},
+		post(message) {
+			latest.contentWindow.postMessage(message, '*');
+		},
+	};
+</script>
+</body>
+</html>
+`
+
+// SetTransition sets the transition used by the following calls of OpenHTML and OpenPDF.
+func (b *BrowserType) SetTransition(transition Transition) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.transition = transition
+}
+
+// showInPlayer loads the player page if it is not shown, e.g. after a website was opened,
+// and switches it to url.
+func (b *BrowserType) showInPlayer(url string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closeWebsite()
+	b.resetZoom()
+	path, err := b.playerFile()
+	if err != nil {
+		return err
+	}
+	playerPath, err := json.Marshal(path)
+	if err != nil {
+		return fmt.Errorf("failed to encode player path: %w", err)
+	}
+	// websites may define a global of the same name, so the location is checked too
+	var loaded bool
+	check := fmt.Sprintf("location.protocol === 'file:' && decodeURI(location.pathname) === %s && typeof window.plgMudicsPlayer === 'object'", playerPath)
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(check, &loaded)); err != nil {
+		return fmt.Errorf("failed to check player: %w", err)
+	}
+	if !loaded {
+		if err := chromedp.Run(b.Ctx, chromedp.Navigate("file://"+path)); err != nil {
+			return fmt.Errorf("failed to open player: %w", err)
+		}
+	}
+
+	arguments, err := json.Marshal([]any{url, b.transition.Kind, b.transition.Duration.Milliseconds()})
+	if err != nil {
+		return fmt.Errorf("failed to encode player arguments: %w", err)
+	}
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(fmt.Sprintf("window.plgMudicsPlayer.show(...%s)", arguments), nil)); err != nil {
+		return fmt.Errorf("failed to show %s in player: %w", url, err)
+	}
+	b.shownURL = url
+	return nil
+}
+
+// ShownURL returns the URL of the item last shown in the player, or "" if the player was
+// replaced by a website since.
+func (b *BrowserType) ShownURL() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.shownURL
+}
+
+// PostMessage sends message to the item last shown in the player, where it arrives as a
+// message event.
+func (b *BrowserType) PostMessage(message any) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(fmt.Sprintf("window.plgMudicsPlayer.post(%s)", data), nil)); err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	return nil
+}
+
+// playerFile writes the player page on first use.
+func (b *BrowserType) playerFile() (string, error) {
+	if b.playerPath != "" {
+		if _, err := os.Stat(b.playerPath); err == nil {
+			return b.playerPath, nil
+		}
+	}
+
+	file, err := os.CreateTemp("", "mudics-player-*.html")
+	if err != nil {
+		return "", fmt.Errorf("could not create player file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(playerHTML); err != nil {
+		return "", fmt.Errorf("could not write player file: %w", err)
+	}
+	b.playerPath = file.Name()
+	return b.playerPath, nil
+}
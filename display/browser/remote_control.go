@@ -0,0 +1,208 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrNotAvailable means a remote control action does not work with the shown content.
+var ErrNotAvailable = errors.New("not available for the shown content")
+
+// Scroll scrolls like a mouse wheel in the center of the screen, so that it also reaches
+// frames of other origins. deltaX and deltaY are in CSS pixels.
+func (b *BrowserType) Scroll(deltaX, deltaY float64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	width, height, err := b.viewportSize()
+	if err != nil {
+		return err
+	}
+	wheel := input.DispatchMouseEvent(input.MouseWheel, width/2, height/2).WithDeltaX(deltaX).WithDeltaY(deltaY)
+	if err := chromedp.Run(b.Ctx, wheel); err != nil {
+		return fmt.Errorf("failed to scroll: %w", err)
+	}
+	return nil
+}
+
+// ScrollTo scrolls the document in the center of the screen to a position from 0 to 1. Only
+// documents of frames with the origin of the page can be reached, like the ones of the player.
+func (b *BrowserType) ScrollTo(x, y float64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var scrolled bool
+	script := fmt.Sprintf("(%s)(%g, %g)", scrollToScript, x, y)
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(script, &scrolled)); err != nil {
+		return fmt.Errorf("failed to scroll: %w", err)
+	}
+	if !scrolled {
+		return fmt.Errorf("%w: the content in the center is a frame of another origin", ErrNotAvailable)
+	}
+	return nil
+}
+
+// scrollToScript follows the frames below the center of the screen down to the innermost
+// document. Overlays are skipped by elementFromPoint, as they do not take pointer events.
+const scrollToScript = `(positionX, positionY) => {
+	let doc = document;
+	let x = window.innerWidth / 2;
+	let y = window.innerHeight / 2;
+	for (;;) {
+		const element = doc.elementFromPoint(x, y);
+		if (!element || element.tagName !== 'IFRAME') {
+			break;
+		}
+		if (!element.contentDocument) {
+			return false;
+		}
+		const rect = element.getBoundingClientRect();
+		x -= rect.left;
+		y -= rect.top;
+		doc = element.contentDocument;
+	}
+	const scroller = doc.scrollingElement || doc.documentElement;
+	scroller.scrollTo({
+		left: positionX * (scroller.scrollWidth - scroller.clientWidth),
+		top: positionY * (scroller.scrollHeight - scroller.clientHeight),
+		behavior: 'smooth',
+	});
+	return true;
+}`
+
+// Zoom magnifies the page like pinching a touchpad. A factor of 1 is no zoom. Websites keep
+// their layout, the zoom is undone when other content is shown.
+func (b *BrowserType) Zoom(factor float64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := chromedp.Run(b.Ctx, emulation.SetPageScaleFactor(factor)); err != nil {
+		return fmt.Errorf("failed to zoom: %w", err)
+	}
+	b.zoomed = factor != 1
+	return nil
+}
+
+// ZoomFactor returns the current zoom of the page, which websites may also change themselves.
+func (b *BrowserType) ZoomFactor() (float64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var factor float64
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(`window.visualViewport.scale`, &factor)); err != nil {
+		return 0, fmt.Errorf("failed to read zoom: %w", err)
+	}
+	return factor, nil
+}
+
+// resetZoom undoes Zoom. The mutex has to be held.
+func (b *BrowserType) resetZoom() {
+	if !b.zoomed {
+		return
+	}
+	chromedp.Run(b.Ctx, emulation.SetPageScaleFactor(1))
+	b.zoomed = false
+}
+
+// Click moves the mouse to a position from 0 to 1 of the screen and clicks count times, e.g.
+// twice for a double click.
+func (b *BrowserType) Click(x, y float64, button input.MouseButton, count int) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	width, height, err := b.viewportSize()
+	if err != nil {
+		return err
+	}
+	x *= width
+	y *= height
+
+	actions := chromedp.Tasks{input.DispatchMouseEvent(input.MouseMoved, x, y)}
+	for i := 1; i <= count; i++ {
+		actions = append(actions,
+			input.DispatchMouseEvent(input.MousePressed, x, y).WithButton(button).WithClickCount(int64(i)),
+			input.DispatchMouseEvent(input.MouseReleased, x, y).WithButton(button).WithClickCount(int64(i)),
+		)
+	}
+	if err := chromedp.Run(b.Ctx, actions); err != nil {
+		return fmt.Errorf("failed to click: %w", err)
+	}
+	return nil
+}
+
+// NavigateBack goes to the previous page of the open website. It does not go back before
+// the page the website was opened with, which would show stale content.
+func (b *BrowserType) NavigateBack() error {
+	return b.navigateHistory(-1)
+}
+
+// NavigateForward goes to the next page of the open website.
+func (b *BrowserType) NavigateForward() error {
+	return b.navigateHistory(1)
+}
+
+// Reload reloads the open website.
+func (b *BrowserType) Reload() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.website == nil {
+		return fmt.Errorf("%w: no website is open", ErrNotAvailable)
+	}
+	if err := chromedp.Run(b.Ctx, page.Reload()); err != nil {
+		return fmt.Errorf("failed to reload: %w", err)
+	}
+	return nil
+}
+
+func (b *BrowserType) navigateHistory(offset int) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.website == nil {
+		return fmt.Errorf("%w: no website is open", ErrNotAvailable)
+	}
+
+	var current int64
+	var entries []*page.NavigationEntry
+	err := chromedp.Run(b.Ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		current, entries, err = page.GetNavigationHistory().Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	// the first entry is gone if the history grew too long
+	first := max(0, slices.IndexFunc(entries, func(entry *page.NavigationEntry) bool {
+		return entry.ID == b.website.firstEntryID
+	}))
+	target := int(current) + offset
+	if target < first || target >= len(entries) {
+		return fmt.Errorf("%w: there is no page to go to", ErrNotAvailable)
+	}
+	if err := chromedp.Run(b.Ctx, page.NavigateToHistoryEntry(entries[target].ID)); err != nil {
+		return fmt.Errorf("failed to navigate: %w", err)
+	}
+	return nil
+}
+
+// viewportSize returns the size of the window in CSS pixels. The mutex has to be held.
+func (b *BrowserType) viewportSize() (float64, float64, error) {
+	var size []float64
+	if err := chromedp.Run(b.Ctx, chromedp.Evaluate(`[window.innerWidth, window.innerHeight]`, &size)); err != nil {
+		return 0, 0, fmt.Errorf("failed to read window size: %w", err)
+	}
+	if len(size) != 2 {
+		return 0, 0, fmt.Errorf("unexpected window size %v", size)
+	}
+	return size[0], size[1], nil
+}
@@ -0,0 +1,204 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+var ErrNavigationFailed = errors.New("navigation failed")
+
+// navigationTimeout is how long OpenPage waits for the website to load.
+const navigationTimeout = 30 * time.Second
+
+// WebsiteOptions change how OpenPage shows a website. They apply until other content is shown.
+type WebsiteOptions struct {
+	// Scripts run in every document of the website before its own scripts, in frames too.
+	Scripts []string
+	// Cookies are set before the website is opened.
+	Cookies  []*network.CookieParam
+	Username string
+	Password string
+	// ReloadInterval reloads the page periodically, 0 never.
+	ReloadInterval time.Duration
+	// Allowed decides which documents may load, in frames too. Nil allows all.
+	Allowed func(url string) bool
+}
+
+// website is the state of an open website, which is undone when other content is shown.
+type website struct {
+	cancel    context.CancelFunc
+	scriptIDs []page.ScriptIdentifier
+	fetching  bool
+	// firstEntryID is the history entry of the opened page, NavigateBack stops there
+	firstEntryID int64
+}
+
+// OpenPage navigates the whole window to url, which replaces the player. Websites are opened
+// like this, as many of them refuse to be shown in a frame. An error wrapping
+// ErrNavigationFailed means the website is shown, but did not load.
+func (b *BrowserType) OpenPage(url string, options WebsiteOptions) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.closeWebsite()
+	b.resetZoom()
+	b.shownURL = ""
+
+	ctx, cancel := context.WithCancel(b.Ctx)
+	b.website = &website{cancel: cancel}
+	if err := b.website.setUp(ctx, url, options); err != nil {
+		b.closeWebsite()
+		return err
+	}
+
+	navigateCtx, cancelNavigate := context.WithTimeout(ctx, navigationTimeout)
+	defer cancelNavigate()
+	response, err := chromedp.RunResponse(navigateCtx, chromedp.Navigate(url))
+	b.website.firstEntryID = currentEntryID(ctx)
+	if options.ReloadInterval > 0 {
+		go b.reload(ctx, options.ReloadInterval)
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: website did not load within %s", ErrNavigationFailed, navigationTimeout)
+	case err != nil:
+		return fmt.Errorf("%w: %w", ErrNavigationFailed, err)
+	case response != nil && response.Status >= 400:
+		return fmt.Errorf("%w: website answered with status %d", ErrNavigationFailed, response.Status)
+	}
+	return nil
+}
+
+// currentEntryID returns the ID of the shown history entry, 0 if it can not be read.
+func currentEntryID(ctx context.Context) int64 {
+	var id int64
+	chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		current, entries, err := page.GetNavigationHistory().Do(ctx)
+		if err == nil && int(current) < len(entries) {
+			id = entries[current].ID
+		}
+		return err
+	}))
+	return id
+}
+
+func (w *website) setUp(ctx context.Context, pageURL string, options WebsiteOptions) error {
+	for _, script := range options.Scripts {
+		var id page.ScriptIdentifier
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			id, err = page.AddScriptToEvaluateOnNewDocument(script).Do(ctx)
+			return err
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to add website script: %w", err)
+		}
+		w.scriptIDs = append(w.scriptIDs, id)
+	}
+
+	if len(options.Cookies) > 0 {
+		if err := chromedp.Run(ctx, network.SetCookies(options.Cookies)); err != nil {
+			return fmt.Errorf("failed to set cookies: %w", err)
+		}
+	}
+
+	auth := options.Username != "" || options.Password != ""
+	if options.Allowed == nil && !auth {
+		return nil
+	}
+	// without credentials, only documents have to be checked
+	patterns := []*fetch.RequestPattern{{URLPattern: "*", ResourceType: network.ResourceTypeDocument}}
+	if auth {
+		patterns = []*fetch.RequestPattern{{URLPattern: "*"}}
+	}
+	listenFetch(ctx, pageURL, options)
+	if err := chromedp.Run(ctx, fetch.Enable().WithPatterns(patterns).WithHandleAuthRequests(auth)); err != nil {
+		return fmt.Errorf("failed to intercept requests: %w", err)
+	}
+	w.fetching = true
+	return nil
+}
+
+// listenFetch blocks documents that are not allowed and answers authentication challenges
+// once per request, so that wrong credentials do not loop. Credentials are only sent to the
+// host of pageURL, not to other sites the page embeds.
+func listenFetch(ctx context.Context, pageURL string, options WebsiteOptions) {
+	host := ""
+	if parsed, err := neturl.Parse(pageURL); err == nil {
+		host = parsed.Host
+	}
+	var answered sync.Map
+	chromedp.ListenTarget(ctx, func(event any) {
+		switch event := event.(type) {
+		case *fetch.EventRequestPaused:
+			action := chromedp.Action(fetch.ContinueRequest(event.RequestID))
+			if event.ResourceType == network.ResourceTypeDocument && options.Allowed != nil && !options.Allowed(event.Request.URL) {
+				action = fetch.FailRequest(event.RequestID, network.ErrorReasonBlockedByClient)
+			}
+			go chromedp.Run(ctx, action)
+
+		case *fetch.EventAuthRequired:
+			response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+			requested, err := neturl.Parse(event.Request.URL)
+			sameHost := err == nil && strings.EqualFold(requested.Host, host)
+			if _, loaded := answered.LoadOrStore(event.RequestID, true); !loaded && sameHost {
+				response = &fetch.AuthChallengeResponse{
+					Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+					Username: options.Username,
+					Password: options.Password,
+				}
+			}
+			go chromedp.Run(ctx, fetch.ContinueWithAuth(event.RequestID, response))
+		}
+	})
+}
+
+// reload reloads the website until other content is shown. Reloads also retry a website
+// that failed to load.
+func (b *BrowserType) reload(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.mutex.Lock()
+		if ctx.Err() == nil {
+			chromedp.Run(ctx, page.Reload())
+		}
+		b.mutex.Unlock()
+	}
+}
+
+// closeWebsite undoes the options of the open website. The mutex has to be held.
+func (b *BrowserType) closeWebsite() {
+	if b.website == nil {
+		return
+	}
+
+	actions := chromedp.Tasks{}
+	if b.website.fetching {
+		actions = append(actions, fetch.Disable())
+	}
+	for _, id := range b.website.scriptIDs {
+		actions = append(actions, page.RemoveScriptToEvaluateOnNewDocument(id))
+	}
+	chromedp.Run(b.Ctx, actions)
+
+	b.website.cancel()
+	b.website = nil
+}
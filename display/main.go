@@ -1,17 +1,34 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log/slog"
 	"os"
 
 	"plg-mudics/display/browser"
 	"plg-mudics/display/pkg"
 	"plg-mudics/display/web"
+	"plg-mudics/shared/config"
 )
 
 func main() {
 	var err error
 
+	err = config.Load(&pkg.Config, "display", os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(2)
+	}
+	err = config.SetLogLevel(pkg.Config.LogLevel)
+	if err != nil {
+		slog.Error("Failed to set log level", "error", err)
+		os.Exit(2)
+	}
+
 	// Ensure local config directory exists
 	_, err = pkg.GetStoragePath()
 	if err != nil {
@@ -19,14 +36,35 @@ func main() {
 		os.Exit(1)
 		return
 	}
-	port := "1323"
+	rolledBack, err := pkg.CheckPendingUpdate()
+	if err != nil {
+		slog.Error("Failed to check for pending update", "error", err)
+	}
+	if rolledBack {
+		slog.Warn("Update was not confirmed, restarting with previous binary")
+		err = pkg.Restart()
+		slog.Error("Failed to restart", "error", err)
+		os.Exit(1)
+	}
 
 	// the order is important, the open browser command exitsts as soon as the winodw is closed
 	// and since its the last action in the main go func all other goroutines (e.g. the webserver) are killed
-	go web.StartWebServer(port)
+	go web.StartWebServer(pkg.Config.Listen)
 
-	browser.Browser.Init()
+	err = browser.Browser.Init(browser.Options{
+		Binary:  pkg.Config.BrowserBinary,
+		Flags:   pkg.Config.BrowserFlags,
+		DataDir: pkg.Config.BrowserDataDir,
+	})
+	if err != nil {
+		slog.Error("Failed to initialize browser", "error", err)
+		os.Exit(1)
+	}
 	pkg.OpenStartScreen()
 	defer browser.Browser.Cancel()
 	<-browser.Browser.Ctx.Done()
+	if pkg.Restarting() {
+		// Restart closed the browser and replaces this process, or exits if that fails
+		select {}
+	}
 }
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheCleanupDelay collects the changes of this long into one cleanup, as shell commands
+// like the ls and find of the control UI run often.
+const cacheCleanupDelay = 10 * time.Second
+
+var cacheCleaner struct {
+	mutex     sync.Mutex
+	scheduled bool
+	// running is held by CleanCaches, so that only one cleanup runs at a time
+	running sync.Mutex
+}
+
+// ScheduleCacheCleanup runs CleanCaches after cacheCleanupDelay, unless it is already
+// scheduled.
+func ScheduleCacheCleanup() {
+	cacheCleaner.mutex.Lock()
+	defer cacheCleaner.mutex.Unlock()
+
+	if cacheCleaner.scheduled {
+		return
+	}
+	cacheCleaner.scheduled = true
+	time.AfterFunc(cacheCleanupDelay, func() {
+		cacheCleaner.mutex.Lock()
+		cacheCleaner.scheduled = false
+		cacheCleaner.mutex.Unlock()
+		CleanCaches()
+	})
+}
+
+// CleanCaches removes previews, rendered presentations and extracted bundles of files that
+// were renamed, changed or deleted.
+func CleanCaches() {
+	cacheCleaner.running.Lock()
+	defer cacheCleaner.running.Unlock()
+
+	if err := previewCache.clean(); err != nil {
+		slog.Error("Failed to clean preview cache", "error", err)
+	}
+	for _, name := range []string{presentationCacheName, bundleCacheName} {
+		if err := cleanCacheDirs(name); err != nil {
+			slog.Error("Failed to clean cache", "cache", name, "error", err)
+		}
+	}
+}
+
+// getCachePath returns a hidden directory in the storage directory, so that it does not
+// show up in the file list.
+func getCachePath(name string) (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	cachePath := filepath.Join(storagePath, "."+name)
+	if err := os.MkdirAll(cachePath, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create %s directory: %w", name, err)
+	}
+	return cachePath, nil
+}
+
+// cacheSource describes the current state of a file and returns the key of its cache entry.
+func cacheSource(path string) (previewIndexEntry, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return previewIndexEntry{}, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+	source := previewIndexEntry{Path: path, Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	return source, previewKey(source, previewVariant{}), nil
+}
+
+// writeCacheSource stores the source of a cache directory, so cleanCacheDirs can check it.
+func writeCacheSource(dir string, source previewIndexEntry) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return fmt.Errorf("failed to encode source: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "source.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write source: %w", err)
+	}
+	return nil
+}
+
+// cleanCacheDirs removes the directories of a cache whose source was renamed, changed or deleted.
+func cleanCacheDirs(name string) error {
+	cachePath, err := getCachePath(name)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(cachePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	for _, entry := range entries {
+		// temporary directories of running conversions
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		dir := filepath.Join(cachePath, entry.Name())
+		var source previewIndexEntry
+		data, err := os.ReadFile(filepath.Join(dir, "source.json"))
+		if err == nil {
+			err = json.Unmarshal(data, &source)
+		}
+		if err == nil {
+			info, statErr := os.Stat(source.Path)
+			if statErr == nil && info.Size() == source.Size && info.ModTime().UnixNano() == source.ModTime {
+				continue
+			}
+		}
+		os.RemoveAll(dir)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"os/exec"
+	"slices"
+
+	"plg-mudics/shared/api"
+)
+
+var externalTools = []string{api.ToolMagick, api.ToolFFmpeg, api.ToolGS, api.ToolSoffice}
+
+// AvailableTools reports which of the external programs are installed.
+func AvailableTools() map[string]bool {
+	tools := map[string]bool{}
+	for _, tool := range externalTools {
+		_, err := exec.LookPath(tool)
+		tools[tool] = err == nil
+	}
+	return tools
+}
+
+// Features lists what this display can do with the given tools installed.
+func Features(tools map[string]bool) []api.Feature {
+	features := []api.Feature{
+		api.FeatureShellCommand,
+		api.FeatureShellCommandDir,
+		api.FeatureKeyboardInput,
+		api.FeaturePointerInput,
+		api.FeatureTypeText,
+		api.FeatureMacro,
+		api.FeatureShowHTML,
+		api.FeatureTakeScreenshot,
+		api.FeatureOpenWebsite,
+		api.FeatureOpenWebsiteOptions,
+		api.FeatureFileTransfer,
+		api.FeatureFileOpen,
+		api.FeatureFileOpenMedia,
+		api.FeatureFileOpenText,
+		api.FeatureFileOpenBundle,
+		api.FeatureDisplayOptions,
+		api.FeatureTransitions,
+		api.FeatureOverlay,
+		api.FeatureLayout,
+		api.FeatureTemplate,
+		api.FeatureTheme,
+		api.FeatureIdleScreen,
+		api.FeatureIdentify,
+		api.FeatureRemoteControl,
+		api.FeatureFileMeta,
+		api.FeatureErrorCodes,
+	}
+
+	if UpdatesEnabled() {
+		features = append(features, api.FeatureUpdate)
+	}
+	if tools[api.ToolSoffice] {
+		features = append(features, api.FeatureFileOpenPresentation, api.FeatureFileOpenDocument)
+		if Config.PrerenderPresentations {
+			features = append(features, api.FeatureFileOpenPresentationSlides)
+		}
+	}
+	if tools[api.ToolMagick] {
+		features = append(features, api.FeatureFilePreview, api.FeatureFilePreviewOptions)
+		if tools[api.ToolGS] {
+			features = append(features, api.FeatureFilePreviewPDF)
+		}
+		if tools[api.ToolFFmpeg] {
+			features = append(features, api.FeatureFilePreviewVideo, api.FeatureFilePreviewAudio, api.FeatureFilePreviewStoryboard)
+		}
+		if tools[api.ToolSoffice] {
+			features = append(features, api.FeatureFilePreviewPresentation)
+		}
+		if tools[api.ToolFFmpeg] {
+			features = append(features, api.FeatureTranscode)
+		}
+	}
+
+	return slices.DeleteFunc(features, func(feature api.Feature) bool {
+		return !Config.FeatureEnabled(feature)
+	})
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"slices"
+
+	"plg-mudics/shared/api"
+)
+
+// Config holds the display settings. Load them with config.Load before anything else runs.
+var Config = ConfigType{
+	Listen:         ":1323",
+	LogLevel:       "info",
+	ControlPort:    api.ControlPort,
+	PreviewWorkers: 2,
+	AudioVisual:    AudioVisualCover,
+}
+
+type ConfigType struct {
+	Listen                 string   `json:"listen" flag:"listen" env:"PLG_MUDICS_LISTEN" usage:"address the API listens on"`
+	StorageDir             string   `json:"storageDir" flag:"storage-dir" env:"PLG_MUDICS_STORAGE_DIR" usage:"directory for uploaded files (default ~/.local/share/plg-mudics/display)"`
+	BrowserBinary          string   `json:"browserBinary" flag:"browser" env:"PLG_MUDICS_BROWSER" usage:"path to the chromium binary (default is looked up in PATH)"`
+	BrowserFlags           []string `json:"browserFlags" flag:"browser-flags" env:"PLG_MUDICS_BROWSER_FLAGS" usage:"additional chromium flags, e.g. disable-gpu,force-device-scale-factor=1"`
+	BrowserDataDir         string   `json:"browserDataDir" flag:"browser-data-dir" env:"PLG_MUDICS_BROWSER_DATA_DIR" usage:"chromium profile directory (default ~/.local/share/plg-mudics/browser-display)"`
+	LogLevel               string   `json:"logLevel" flag:"log-level" env:"PLG_MUDICS_LOG_LEVEL" usage:"debug, info, warn or error"`
+	ControlPort            int      `json:"controlPort" flag:"control-port" env:"PLG_MUDICS_CONTROL_PORT" usage:"port of a local control server, the start screen shows a QR code for it"`
+	DisabledFeatures       []string `json:"disabledFeatures" flag:"disable" env:"PLG_MUDICS_DISABLE" usage:"features to turn off, e.g. shellCommand,update"`
+	PreviewWorkers         int      `json:"previewWorkers" flag:"preview-workers" env:"PLG_MUDICS_PREVIEW_WORKERS" usage:"number of previews generated at the same time"`
+	TranscodeUploads       bool     `json:"transcodeUploads" flag:"transcode-uploads" env:"PLG_MUDICS_TRANSCODE_UPLOADS" usage:"convert uploaded videos and images into formats the display can show"`
+	TranscodeMaxHeight     int      `json:"transcodeMaxHeight" flag:"transcode-max-height" env:"PLG_MUDICS_TRANSCODE_MAX_HEIGHT" usage:"height videos are scaled down to when transcoding (default is the screen height)"`
+	PrerenderPresentations bool     `json:"prerenderPresentations" flag:"prerender-presentations" env:"PLG_MUDICS_PRERENDER_PRESENTATIONS" usage:"convert presentations into slide images on upload and show them in the browser instead of soffice"`
+	AudioVisual            string   `json:"audioVisual" flag:"audio-visual" env:"PLG_MUDICS_AUDIO_VISUAL" usage:"what is shown while audio plays: cover (cover art, else the background) or background"`
+	AudioBackground        string   `json:"audioBackground" flag:"audio-background" env:"PLG_MUDICS_AUDIO_BACKGROUND" usage:"storage-relative path of an image shown while audio plays"`
+	WebsiteAllow           []string `json:"websiteAllow" flag:"website-allow" env:"PLG_MUDICS_WEBSITE_ALLOW" usage:"hosts websites may be opened from, with their subdomains and an optional path prefix, e.g. example.com,intranet.local/news (default all)"`
+	WebsiteDeny            []string `json:"websiteDeny" flag:"website-deny" env:"PLG_MUDICS_WEBSITE_DENY" usage:"hosts websites may not be opened from, like website-allow but checked first"`
+	KeyboardLayout         string   `json:"keyboardLayout" flag:"keyboard-layout" env:"PLG_MUDICS_KEYBOARD_LAYOUT" usage:"XKB layout of the session for typing text: us, de or de(nodeadkeys) (default is read with setxkbmap)"`
+	UpdateToken            string   `json:"updateToken" env:"PLG_MUDICS_UPDATE_TOKEN" usage:"shared secret that authorizes pushed updates, updates are disabled without it"`
+	UpdatePublicKey        string   `json:"updatePublicKey" env:"PLG_MUDICS_UPDATE_PUBLIC_KEY" usage:"base64 ed25519 public key the signatures of updates are checked with"`
+	AllowUnsignedUpdates   bool     `json:"allowUnsignedUpdates" flag:"allow-unsigned-updates" env:"PLG_MUDICS_ALLOW_UNSIGNED_UPDATES" usage:"accept updates without a signature"`
+}
+
+// Values of ConfigType.AudioVisual.
+const (
+	AudioVisualCover      = "cover"
+	AudioVisualBackground = "background"
+)
+
+func (c ConfigType) FeatureEnabled(feature api.Feature) bool {
+	return !slices.Contains(c.DisabledFeatures, string(feature))
+}
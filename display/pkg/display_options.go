@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrDisplayOptionsInvalid = errors.New("invalid display options")
+
+var displayDefaults = displayDefaultsType{}
+
+// displayDefaultsType holds the display options that are used when a request does not set
+// them. They are stored in the storage directory, so they survive restarts.
+type displayDefaultsType struct {
+	mutex   sync.Mutex
+	loaded  bool
+	options api.DisplayOptions
+}
+
+// cssColor allows hex colors, color names and color functions, but nothing that could end
+// the CSS declaration.
+var cssColor = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|[a-z]+\([0-9a-zA-Z.,%/ -]*\))$`)
+
+var alignPositions = map[api.DisplayAlign]string{
+	api.DisplayAlignCenter:      "center center",
+	api.DisplayAlignTop:         "center top",
+	api.DisplayAlignBottom:      "center bottom",
+	api.DisplayAlignLeft:        "left center",
+	api.DisplayAlignRight:       "right center",
+	api.DisplayAlignTopLeft:     "left top",
+	api.DisplayAlignTopRight:    "right top",
+	api.DisplayAlignBottomLeft:  "left bottom",
+	api.DisplayAlignBottomRight: "right bottom",
+}
+
+func getDisplayOptionsPath() (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	// hidden, so that it does not show up in the file list
+	return filepath.Join(storagePath, ".display-options.json"), nil
+}
+
+// GetDisplayDefaults returns the stored defaults. Unset fields are empty.
+func GetDisplayDefaults() (api.DisplayOptions, error) {
+	displayDefaults.mutex.Lock()
+	defer displayDefaults.mutex.Unlock()
+	return displayDefaults.load()
+}
+
+// SetDisplayDefaults validates and stores the defaults.
+func SetDisplayDefaults(options api.DisplayOptions) error {
+	if err := validateDisplayOptions(options); err != nil {
+		return err
+	}
+
+	path, err := getDisplayOptionsPath()
+	if err != nil {
+		return err
+	}
+
+	displayDefaults.mutex.Lock()
+	defer displayDefaults.mutex.Unlock()
+
+	if err := writeJSONFile(path, options); err != nil {
+		return fmt.Errorf("failed to write display options: %w", err)
+	}
+
+	displayDefaults.options = options
+	displayDefaults.loaded = true
+	return nil
+}
+
+func (dd *displayDefaultsType) load() (api.DisplayOptions, error) {
+	if dd.loaded {
+		return dd.options, nil
+	}
+
+	path, err := getDisplayOptionsPath()
+	if err != nil {
+		return api.DisplayOptions{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		dd.loaded = true
+		return dd.options, nil
+	}
+	if err != nil {
+		return api.DisplayOptions{}, fmt.Errorf("failed to read display options: %w", err)
+	}
+	if err := json.Unmarshal(data, &dd.options); err != nil {
+		return api.DisplayOptions{}, fmt.Errorf("failed to parse display options: %w", err)
+	}
+
+	dd.loaded = true
+	return dd.options, nil
+}
+
+// ResolveDisplayOptions applies the options of a request on top of the stored defaults and
+// fills the remaining fields.
+func ResolveDisplayOptions(override api.DisplayOptions) (api.DisplayOptions, error) {
+	if err := validateDisplayOptions(override); err != nil {
+		return api.DisplayOptions{}, err
+	}
+	defaults, err := GetDisplayDefaults()
+	if err != nil {
+		return api.DisplayOptions{}, err
+	}
+
+	options := api.DisplayOptions{
+		Fit:                api.DisplayDefaultFit,
+		Rotation:           new(int),
+		Background:         api.DisplayDefaultBackground,
+		Align:              api.DisplayDefaultAlign,
+		Transition:         api.DisplayDefaultTransition,
+		TransitionDuration: api.DisplayDefaultTransitionDuration,
+	}
+	return options.Merge(defaults).Merge(override), nil
+}
+
+// applyTransition makes the browser switch to the next content with the transition of the
+// resolved options.
+func applyTransition(options api.DisplayOptions) {
+	transition := browser.Transition{
+		Kind:     string(options.Transition),
+		Duration: time.Duration(options.TransitionDuration) * time.Millisecond,
+	}
+	if !Config.FeatureEnabled(api.FeatureTransitions) {
+		transition.Kind = browser.TransitionCut
+	}
+	browser.Browser.SetTransition(transition)
+}
+
+func validateDisplayOptions(options api.DisplayOptions) error {
+	if options.Fit != "" && !slices.Contains(options.Fit.EnumValues(), string(options.Fit)) {
+		return fmt.Errorf("%w: fit has to be one of %s", ErrDisplayOptionsInvalid, strings.Join(options.Fit.EnumValues(), ", "))
+	}
+	if options.Rotation != nil && !slices.Contains([]int{0, 90, 180, 270}, *options.Rotation) {
+		return fmt.Errorf("%w: rotation has to be 0, 90, 180 or 270", ErrDisplayOptionsInvalid)
+	}
+	if options.Background != "" && options.Background != api.DisplayBackgroundBlur && !cssColor.MatchString(options.Background) {
+		return fmt.Errorf("%w: background has to be a CSS color or %s", ErrDisplayOptionsInvalid, api.DisplayBackgroundBlur)
+	}
+	if options.Align != "" && alignPositions[options.Align] == "" {
+		return fmt.Errorf("%w: align has to be one of %s", ErrDisplayOptionsInvalid, strings.Join(options.Align.EnumValues(), ", "))
+	}
+	if options.Transition != "" && !slices.Contains(options.Transition.EnumValues(), string(options.Transition)) {
+		return fmt.Errorf("%w: transition has to be one of %s", ErrDisplayOptionsInvalid, strings.Join(options.Transition.EnumValues(), ", "))
+	}
+	if options.TransitionDuration < 0 || options.TransitionDuration > api.DisplayMaxTransitionDuration {
+		return fmt.Errorf("%w: transitionDuration has to be between 0 and %d", ErrDisplayOptionsInvalid, api.DisplayMaxTransitionDuration)
+	}
+	return nil
+}
+
+// displayOptionsCSS lays out the .stage element of the media templates. The options have to
+// be resolved.
+func displayOptionsCSS(options api.DisplayOptions) string {
+	width, height := "100vw", "100vh"
+	if *options.Rotation == 90 || *options.Rotation == 270 {
+		width, height = height, width
+	}
+	background := options.Background
+	if background == api.DisplayBackgroundBlur {
+		background = api.DisplayDefaultBackground
+	}
+
+	return fmt.Sprintf(`:root { --background-color: %s; }
+.stage { position: fixed; top: 50%%; left: 50%%; width: %s; height: %s; overflow: hidden; transform: translate(-50%%, -50%%) rotate(%ddeg); }
+.stage > img, .stage > video { position: absolute; inset: 0; width: 100%%; height: 100%%; object-fit: %s; object-position: %s; }
+.stage > .blur { object-fit: cover; filter: blur(3vmax) brightness(0.6); transform: scale(1.1); }
+`, background, width, height, *options.Rotation, options.Fit, alignPositions[options.Align])
+}
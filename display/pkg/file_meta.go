@@ -0,0 +1,263 @@
+package pkg
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"plg-mudics/shared"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/shared/api"
+)
+
+var errMetaToolMissing = errors.New("tool for reading metadata is missing")
+
+// GetFileMeta reads the media metadata of a file. Missing tools only leave out fields.
+func GetFileMeta(path string) (api.FileMetaResponse, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return api.FileMetaResponse{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	mType, err := mimetype.DetectFile(path)
+	if err != nil {
+		return api.FileMetaResponse{}, fmt.Errorf("failed to detect mime type: %w", err)
+	}
+
+	meta := api.FileMetaResponse{MimeType: mType.String(), Size: info.Size()}
+
+	switch {
+	case mType.Is("application/pdf"):
+		err = readPDFMeta(path, &meta)
+	case isPresentation(mType):
+		err = readPresentationMeta(path, mType, &meta)
+	case strings.HasPrefix(mType.String(), "video/"), strings.HasPrefix(mType.String(), "audio/"):
+		err = readStreamMeta(path, &meta)
+	case strings.HasPrefix(mType.String(), "image/"):
+		err = readImageMeta(path, &meta)
+	}
+	if errors.Is(err, errMetaToolMissing) {
+		slog.Warn("Metadata is incomplete", "file", path, "error", err)
+		err = nil
+	}
+
+	return meta, err
+}
+
+// runMetaTool runs cmd and returns its stdout.
+func runMetaTool(cmd *exec.Cmd) (string, error) {
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return "", fmt.Errorf("%w: %s", errMetaToolMissing, cmd.Args[0])
+	}
+
+	result := shared.RunShellCommand(cmd)
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("%s failed (%d): %s", cmd.Args[0], result.ExitCode, result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Profile      string `json:"profile"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		Disposition  struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+func readStreamMeta(path string, meta *api.FileMetaResponse) error {
+	output, err := runMetaTool(exec.Command("ffprobe", "-v", "error", "-print_format", "json", "-show_format", "-show_streams", path))
+	if err != nil {
+		return err
+	}
+
+	var probe ffprobeOutput
+	if err := json.Unmarshal([]byte(output), &probe); err != nil {
+		return fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	meta.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	hasAudio := false
+	meta.HasAudio = &hasAudio
+	for _, stream := range probe.Streams {
+		switch {
+		// cover art of audio files is a video stream as well
+		case stream.CodecType == "video" && stream.Disposition.AttachedPic == 0 && meta.VideoCodec == "":
+			meta.VideoCodec = stream.CodecName
+			meta.VideoProfile = stream.Profile
+			meta.Width = stream.Width
+			meta.Height = stream.Height
+			meta.FrameRate = parseFrameRate(stream.AvgFrameRate)
+		case stream.CodecType == "audio" && !hasAudio:
+			hasAudio = true
+			meta.AudioCodec = stream.CodecName
+		}
+	}
+
+	return nil
+}
+
+// parseFrameRate parses the fractions of ffprobe, e.g. "30000/1001".
+func parseFrameRate(raw string) float64 {
+	numerator, denominator, ok := strings.Cut(raw, "/")
+	n, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0
+	}
+	if !ok {
+		return n
+	}
+	d, err := strconv.ParseFloat(denominator, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+func readImageMeta(path string, meta *api.FileMetaResponse) error {
+	// [0] only reads the first frame of animated images
+	output, err := runMetaTool(exec.Command("magick", "identify", "-format", "%w %h %[EXIF:Orientation]", path+"[0]"))
+	if errors.Is(err, errMetaToolMissing) {
+		return readImageMetaFallback(path, meta)
+	}
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) >= 2 {
+		meta.Width, _ = strconv.Atoi(fields[0])
+		meta.Height, _ = strconv.Atoi(fields[1])
+	}
+	if len(fields) >= 3 {
+		meta.Orientation, _ = strconv.Atoi(fields[2])
+	}
+	return nil
+}
+
+// readImageMetaFallback reads the dimensions of JPEG, PNG and GIF without external tools.
+func readImageMetaFallback(path string, meta *api.FileMetaResponse) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return fmt.Errorf("%w: magick", errMetaToolMissing)
+	}
+	meta.Width = config.Width
+	meta.Height = config.Height
+	return nil
+}
+
+func readPDFMeta(path string, meta *api.FileMetaResponse) error {
+	// the path is passed as a PostScript string, so it has to be escaped
+	escaped := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(path)
+	program := fmt.Sprintf("(%s) (r) file runpdfbegin pdfpagecount = quit", escaped)
+	output, err := runMetaTool(exec.Command("gs", "-q", "-dNODISPLAY", "-dSAFER", "--permit-file-read="+path, "-c", program))
+	if err != nil {
+		return err
+	}
+
+	meta.Pages, err = strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return fmt.Errorf("unexpected page count %q", output)
+	}
+	return nil
+}
+
+// readPresentationMeta counts the slides from the statistics that PPTX and ODP files contain.
+// The old binary PPT format is not supported.
+func readPresentationMeta(path string, mType *mimetype.MIME, meta *api.FileMetaResponse) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		// e.g. PPT
+		return nil
+	}
+	defer archive.Close()
+
+	if mType.Is("application/vnd.oasis.opendocument.presentation") {
+		var document struct {
+			Statistic struct {
+				PageCount int `xml:"page-count,attr"`
+			} `xml:"meta>document-statistic"`
+		}
+		if err := decodeZipXML(archive, "meta.xml", &document); err != nil {
+			return err
+		}
+		meta.Pages = document.Statistic.PageCount
+		if meta.Pages == 0 {
+			meta.Pages, err = countODPSlides(archive)
+		}
+		return err
+	}
+
+	var properties struct {
+		Slides int `xml:"Slides"`
+	}
+	if err := decodeZipXML(archive, "docProps/app.xml", &properties); err != nil {
+		return err
+	}
+	meta.Pages = properties.Slides
+	return nil
+}
+
+// countODPSlides counts the draw:page elements, for files without statistics.
+func countODPSlides(archive *zip.ReadCloser) (int, error) {
+	file, err := archive.Open("content.xml")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open content.xml: %w", err)
+	}
+	defer file.Close()
+
+	slides := 0
+	decoder := xml.NewDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return slides, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse content.xml: %w", err)
+		}
+		if element, ok := token.(xml.StartElement); ok && element.Name.Local == "page" && strings.HasSuffix(element.Name.Space, ":drawing:1.0") {
+			slides++
+		}
+	}
+}
+
+func decodeZipXML(archive *zip.ReadCloser, name string, target any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(file, 1<<20)).Decode(target); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
@@ -1,86 +1,488 @@
 package pkg
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"plg-mudics/shared"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/shared/api"
 )
 
 var ErrFileTypePreviewNotSupported = errors.New("file type not supported for preview")
 var ErrFilePreviewToolsMissing = errors.New("required tools for file preview are missing")
+var ErrPreviewOptionsInvalid = errors.New("invalid preview options")
 
-func GenerateFilePreview(inputPath string) (string, error) {
-	var err error
-	ext := strings.ToLower(filepath.Ext(inputPath))
-	tempFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("preview_%d.webp", time.Now().Unix()))
-
-	switch ext {
-	case ".png", ".jpg", ".jpeg", ".bmp", ".gif", ".tiff", ".webp":
-		err = generateImagePreview(inputPath, tempFilePath)
-	case ".pdf":
-		err = generatePDFPreview(inputPath, tempFilePath)
-	case ".mp4", ".mov":
-		err = generateVideoPreview(inputPath, tempFilePath)
-	default:
-		return "", ErrFileTypePreviewNotSupported
+var previewCache = previewCacheType{inFlight: map[string]*previewCall{}}
+
+// previewCacheType stores generated previews in the storage directory, keyed by the path,
+// size and modification time of the source file. Generation runs on a bounded number of workers.
+type previewCacheType struct {
+	mutex    sync.Mutex
+	index    map[string]previewIndexEntry
+	inFlight map[string]*previewCall
+	workers  chan struct{}
+}
+
+// previewIndexEntry remembers the source of a cached preview to detect stale entries.
+type previewIndexEntry struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"`
+	// File is the name of the preview in the cache directory.
+	File string `json:"file"`
+}
+
+// previewVariant is a validated api.PreviewOptions with the defaults applied.
+type previewVariant struct {
+	Width      int
+	Height     int
+	Fit        api.PreviewFit
+	Format     api.PreviewFormat
+	Storyboard int
+}
+
+func (v previewVariant) String() string {
+	return fmt.Sprintf("%dx%d-%s-%s-%d", v.Width, v.Height, v.Fit, v.Format, v.Storyboard)
+}
+
+func (v previewVariant) quality() string {
+	// small previews are only used as icons
+	if v.Width <= api.PreviewDefaultSize {
+		return "50"
 	}
+	return "80"
+}
+
+type previewCall struct {
+	done chan struct{}
+	err  error
+}
+
+type Preview struct {
+	Path string
+	ETag string
+}
+
+// GetFilePreview returns the cached preview of a file and generates it if needed.
+func GetFilePreview(ctx context.Context, inputPath string, options api.PreviewOptions) (Preview, error) {
+	variant, err := newPreviewVariant(options)
 	if err != nil {
-		return "", err
+		return Preview{}, err
 	}
+	return previewCache.get(ctx, inputPath, variant)
+}
 
-	return tempFilePath, nil
+// previewMaxWidths are the widest images the encoders of the formats can write.
+var previewMaxWidths = map[api.PreviewFormat]int{
+	api.PreviewFormatWebP: api.PreviewMaxWidthWebP,
+	api.PreviewFormatJPEG: api.PreviewMaxWidthJPEG,
 }
 
-func generateImagePreview(inputPath string, outputPath string) error {
-	cmd := exec.Command("magick", inputPath, "-gravity", "center", "-crop", "1:1", "-thumbnail", "100", "-quality", "50", outputPath)
-	result := shared.RunShellCommand(cmd)
-	if result.ExitCode != 0 {
-		if result.ExitCode == 127 {
-			return ErrFilePreviewToolsMissing
+func newPreviewVariant(options api.PreviewOptions) (previewVariant, error) {
+	variant := previewVariant{
+		Width:      cmp.Or(options.Size, api.PreviewDefaultSize),
+		Fit:        cmp.Or(options.Fit, api.PreviewDefaultFit),
+		Format:     cmp.Or(options.Format, api.PreviewDefaultFormat),
+		Storyboard: options.Storyboard,
+	}
+
+	if variant.Width < api.PreviewMinSize || variant.Width > api.PreviewMaxSize {
+		return previewVariant{}, fmt.Errorf("%w: size has to be between %d and %d", ErrPreviewOptionsInvalid, api.PreviewMinSize, api.PreviewMaxSize)
+	}
+	if !slices.Contains(variant.Fit.EnumValues(), string(variant.Fit)) {
+		return previewVariant{}, fmt.Errorf("%w: unknown fit %q", ErrPreviewOptionsInvalid, variant.Fit)
+	}
+	if !slices.Contains(variant.Format.EnumValues(), string(variant.Format)) {
+		return previewVariant{}, fmt.Errorf("%w: unknown format %q", ErrPreviewOptionsInvalid, variant.Format)
+	}
+	if variant.Storyboard < 0 || variant.Storyboard > api.PreviewMaxStoryboard {
+		return previewVariant{}, fmt.Errorf("%w: storyboard has to be between 0 and %d", ErrPreviewOptionsInvalid, api.PreviewMaxStoryboard)
+	}
+	if maxWidth := previewMaxWidths[variant.Format]; maxWidth > 0 && variant.Width*max(variant.Storyboard, 1) > maxWidth {
+		return previewVariant{}, fmt.Errorf("%w: size times storyboard has to be at most %d for %s", ErrPreviewOptionsInvalid, maxWidth, variant.Format)
+	}
+
+	aspectWidth, aspectHeight, ok := strings.Cut(cmp.Or(options.Aspect, api.PreviewDefaultAspect), ":")
+	ratioWidth, errWidth := strconv.Atoi(aspectWidth)
+	ratioHeight, errHeight := strconv.Atoi(aspectHeight)
+	if !ok || errWidth != nil || errHeight != nil || ratioWidth <= 0 || ratioHeight <= 0 {
+		return previewVariant{}, fmt.Errorf("%w: aspect has to look like 16:9", ErrPreviewOptionsInvalid)
+	}
+	variant.Height = max(variant.Width*ratioHeight/ratioWidth, 1)
+	if variant.Height > api.PreviewMaxSize {
+		return previewVariant{}, fmt.Errorf("%w: height would exceed %d", ErrPreviewOptionsInvalid, api.PreviewMaxSize)
+	}
+
+	return variant, nil
+}
+
+func getPreviewCachePath() (string, error) {
+	return getCachePath("preview-cache")
+}
+
+func (pc *previewCacheType) get(ctx context.Context, inputPath string, variant previewVariant) (Preview, error) {
+	generate, err := previewGenerator(inputPath, variant)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return Preview{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	cachePath, err := getPreviewCachePath()
+	if err != nil {
+		return Preview{}, err
+	}
+
+	source := previewIndexEntry{Path: inputPath, Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	key := previewKey(source, variant)
+	source.File = key + "." + string(variant.Format)
+	preview := Preview{Path: filepath.Join(cachePath, source.File), ETag: key}
+
+	pc.mutex.Lock()
+	if err := pc.loadIndex(cachePath); err != nil {
+		pc.mutex.Unlock()
+		return Preview{}, err
+	}
+	if _, ok := pc.index[key]; ok {
+		pc.mutex.Unlock()
+		if _, err := os.Stat(preview.Path); err == nil {
+			return preview, nil
+		}
+		pc.mutex.Lock()
+		delete(pc.index, key)
+	}
+	call, running := pc.inFlight[key]
+	if !running {
+		call = &previewCall{done: make(chan struct{})}
+		pc.inFlight[key] = call
+	}
+	pc.mutex.Unlock()
+
+	if !running {
+		go pc.generate(key, source, variant, preview.Path, generate, call)
+	}
+
+	select {
+	case <-call.done:
+		return preview, call.err
+	case <-ctx.Done():
+		return Preview{}, ctx.Err()
+	}
+}
+
+func (pc *previewCacheType) generate(key string, source previewIndexEntry, variant previewVariant, outputPath string, generate previewGeneratorFunc, call *previewCall) {
+	pc.workers <- struct{}{}
+	defer func() { <-pc.workers }()
+
+	// write into a unique temp file first, so that a half written preview is never served
+	tempFile, err := os.CreateTemp(filepath.Dir(outputPath), ".preview-*"+filepath.Ext(outputPath))
+	if err == nil {
+		tempFile.Close()
+		err = generate(source.Path, tempFile.Name(), variant)
+		if err == nil {
+			err = os.Rename(tempFile.Name(), outputPath)
+		}
+		os.Remove(tempFile.Name())
+	}
+
+	pc.mutex.Lock()
+	if err == nil {
+		pc.index[key] = source
+		err = pc.saveIndex(filepath.Dir(outputPath))
+	}
+	delete(pc.inFlight, key)
+	pc.mutex.Unlock()
+
+	call.err = err
+	close(call.done)
+}
+
+func (pc *previewCacheType) clean() error {
+	cachePath, err := getPreviewCachePath()
+	if err != nil {
+		return err
+	}
+
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	if err := pc.loadIndex(cachePath); err != nil {
+		return err
+	}
+
+	for key, source := range pc.index {
+		info, err := os.Stat(source.Path)
+		if err == nil && info.Size() == source.Size && info.ModTime().UnixNano() == source.ModTime {
+			continue
+		}
+		os.Remove(filepath.Join(cachePath, cmp.Or(source.File, key+".webp")))
+		delete(pc.index, key)
+	}
+
+	// previews that are missing in the index, e.g. after a crash
+	entries, err := os.ReadDir(cachePath)
+	if err != nil {
+		return fmt.Errorf("failed to read preview cache: %w", err)
+	}
+	for _, entry := range entries {
+		key := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if _, ok := pc.index[key]; !ok && pc.inFlight[key] == nil && !strings.HasPrefix(key, ".") && key != "index" {
+			os.Remove(filepath.Join(cachePath, entry.Name()))
 		}
-		return errors.New(result.Stderr)
+	}
+
+	return pc.saveIndex(cachePath)
+}
+
+func (pc *previewCacheType) loadIndex(cachePath string) error {
+	if pc.index != nil {
+		return nil
+	}
+	if pc.workers == nil {
+		pc.workers = make(chan struct{}, max(Config.PreviewWorkers, 1))
+	}
+
+	pc.index = map[string]previewIndexEntry{}
+	data, err := os.ReadFile(filepath.Join(cachePath, "index.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read preview cache index: %w", err)
+	}
+	if err := json.Unmarshal(data, &pc.index); err != nil {
+		slog.Warn("Preview cache index is corrupt, starting with an empty cache", "error", err)
+		pc.index = map[string]previewIndexEntry{}
 	}
 	return nil
 }
 
-func generatePDFPreview(inputPath string, outputPath string) error {
-	testCmd := exec.Command("which", "gs")
-	if result := shared.RunShellCommand(testCmd); result.ExitCode != 0 {
-		return ErrFilePreviewToolsMissing
+func (pc *previewCacheType) saveIndex(cachePath string) error {
+	data, err := json.Marshal(pc.index)
+	if err != nil {
+		return fmt.Errorf("failed to encode preview cache index: %w", err)
+	}
+	tempPath := filepath.Join(cachePath, ".index.json.tmp")
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write preview cache index: %w", err)
+	}
+	return os.Rename(tempPath, filepath.Join(cachePath, "index.json"))
+}
+
+func previewKey(source previewIndexEntry, variant previewVariant) string {
+	hash := sha256.Sum256([]byte(source.Path + "\x00" + strconv.FormatInt(source.Size, 10) + "\x00" + strconv.FormatInt(source.ModTime, 10) + "\x00" + variant.String()))
+	return hex.EncodeToString(hash[:16])
+}
+
+type previewGeneratorFunc func(inputPath string, outputPath string, variant previewVariant) error
+
+func previewGenerator(inputPath string, variant previewVariant) (previewGeneratorFunc, error) {
+	mType, err := mimetype.DetectFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect mime type: %w", err)
+	}
+	mediaType := strings.Split(mType.String(), "/")[0]
+
+	if variant.Storyboard > 0 {
+		if mediaType != "video" {
+			return nil, fmt.Errorf("%w: storyboards are only available for videos, not %s", ErrFileTypePreviewNotSupported, mType.String())
+		}
+		return generateStoryboardPreview, nil
+	}
+
+	switch {
+	case mType.Is("image/svg+xml"):
+		return generateSVGPreview, nil
+	case mType.Is("application/pdf"):
+		return generatePDFPreview, nil
+	case isPresentation(mType):
+		return generatePresentationPreview, nil
+	}
+
+	switch mediaType {
+	case "image":
+		return generateImagePreview, nil
+	case "video":
+		return generateVideoPreview, nil
+	case "audio":
+		return generateAudioPreview, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrFileTypePreviewNotSupported, mType.String())
+	}
+}
+
+func isPresentation(mType *mimetype.MIME) bool {
+	return mType.Is("application/vnd.openxmlformats-officedocument.presentationml.presentation") ||
+		mType.Is("application/vnd.oasis.opendocument.presentation") ||
+		mType.Is("application/vnd.ms-powerpoint")
+}
+
+// runPreviewTool runs one of the external programs used for previews.
+func runPreviewTool(cmd *exec.Cmd) error {
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return fmt.Errorf("%w: %s", ErrFilePreviewToolsMissing, cmd.Args[0])
 	}
 
-	cmd := exec.Command("magick", fmt.Sprintf("%s[0]", inputPath), "-gravity", "center", "-crop", "1:1", "-thumbnail", "100x100", "-quality", "50", outputPath)
 	result := shared.RunShellCommand(cmd)
 	if result.ExitCode != 0 {
-		if result.ExitCode == 127 {
-			return ErrFilePreviewToolsMissing
-		}
-		return errors.New(result.Stderr)
+		return fmt.Errorf("%s failed (%d): %s", cmd.Args[0], result.ExitCode, result.Stderr)
 	}
 	return nil
 }
 
-func generateVideoPreview(inputPath string, outputPath string) error {
-	tempFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("preview_temp_%d.webp", time.Now().Unix()))
+// magickResizeArgs scales the current image to the size of the variant.
+func magickResizeArgs(variant previewVariant) []string {
+	size := fmt.Sprintf("%dx%d", variant.Width, variant.Height)
+	if variant.Fit == api.PreviewFitContain {
+		return []string{"-thumbnail", size, "-quality", variant.quality()}
+	}
+	return []string{"-thumbnail", size + "^", "-gravity", "center", "-extent", size, "-quality", variant.quality()}
+}
 
-	ffmpegCmd := exec.Command("ffmpeg", "-i", inputPath, "-ss", "00:00:01.000", "-vframes", "1", tempFilePath)
-	result := shared.RunShellCommand(ffmpegCmd)
-	if result.ExitCode != 0 {
-		if result.ExitCode == 127 {
-			return ErrFilePreviewToolsMissing
-		}
-		return errors.New(result.Stderr)
+// ffmpegScaleFilter is the ffmpeg counterpart of magickResizeArgs. Contained frames are padded,
+// so that all frames of a storyboard have the same size.
+func ffmpegScaleFilter(variant previewVariant) string {
+	if variant.Fit == api.PreviewFitContain {
+		return fmt.Sprintf("scale=%[1]d:%[2]d:force_original_aspect_ratio=decrease,pad=%[1]d:%[2]d:-1:-1", variant.Width, variant.Height)
+	}
+	return fmt.Sprintf("scale=%[1]d:%[2]d:force_original_aspect_ratio=increase,crop=%[1]d:%[2]d", variant.Width, variant.Height)
+}
+
+func generateImagePreview(inputPath string, outputPath string, variant previewVariant) error {
+	// [0] selects the first frame of animated images
+	args := append([]string{inputPath + "[0]", "-auto-orient"}, magickResizeArgs(variant)...)
+	cmd := exec.Command("magick", append(args, outputPath)...)
+	return runPreviewTool(cmd)
+}
+
+func generateSVGPreview(inputPath string, outputPath string, variant previewVariant) error {
+	args := append([]string{"-background", "none", "-density", "192", "svg:" + inputPath}, magickResizeArgs(variant)...)
+	cmd := exec.Command("magick", append(args, outputPath)...)
+	return runPreviewTool(cmd)
+}
+
+func generatePDFPreview(inputPath string, outputPath string, variant previewVariant) error {
+	if _, err := exec.LookPath("gs"); err != nil {
+		return fmt.Errorf("%w: gs", ErrFilePreviewToolsMissing)
+	}
+
+	return generateImagePreview(inputPath, outputPath, variant)
+}
+
+func generatePresentationPreview(inputPath string, outputPath string, variant previewVariant) error {
+	tempDir, err := os.MkdirTemp("", "plg-mudics-preview-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// soffice only exports the first slide when converting to an image; a separate profile
+	// keeps it from attaching to an already running instance
+	cmd := exec.Command("soffice", "--headless", "--convert-to", "png", "--outdir", tempDir, fmt.Sprintf("-env:UserInstallation=file://%s", filepath.Join(tempDir, "profile")), inputPath)
+	if err := runPreviewTool(cmd); err != nil {
+		return err
+	}
+
+	slidePath := filepath.Join(tempDir, strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))+".png")
+	return generateImagePreview(slidePath, outputPath, variant)
+}
+
+func generateVideoPreview(inputPath string, outputPath string, variant previewVariant) error {
+	tempFile, err := os.CreateTemp("", "preview-frame-*.png")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
 	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	// the thumbnail filter picks a representative frame and also works for videos shorter than a second
+	ffmpegCmd := exec.Command("ffmpeg", "-y", "-i", inputPath, "-map", "0:v:0", "-vf", "thumbnail", "-frames:v", "1", tempFile.Name())
+	if err := runPreviewTool(ffmpegCmd); err != nil {
+		return err
+	}
+
+	return generateImagePreview(tempFile.Name(), outputPath, variant)
+}
 
-	err := generateImagePreview(tempFilePath, outputPath)
+// generateStoryboardPreview places frames from evenly spaced points in time next to each other.
+func generateStoryboardPreview(inputPath string, outputPath string, variant previewVariant) error {
+	duration, err := videoDuration(inputPath)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	tempFile, err := os.CreateTemp("", "preview-storyboard-*.png")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	frameRate := float64(variant.Storyboard) / duration
+	filter := fmt.Sprintf("fps=%f,%s,tile=%dx1", frameRate, ffmpegScaleFilter(variant), variant.Storyboard)
+	ffmpegCmd := exec.Command("ffmpeg", "-y", "-i", inputPath, "-map", "0:v:0", "-vf", filter, "-frames:v", "1", tempFile.Name())
+	if err := runPreviewTool(ffmpegCmd); err != nil {
+		return err
+	}
+
+	// only converts the format, the frames already have the right size
+	cmd := exec.Command("magick", tempFile.Name(), "-quality", variant.quality(), outputPath)
+	return runPreviewTool(cmd)
+}
+
+func videoDuration(inputPath string) (float64, error) {
+	cmd := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", inputPath)
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrFilePreviewToolsMissing, cmd.Args[0])
+	}
+
+	result := shared.RunShellCommand(cmd)
+	if result.ExitCode != 0 {
+		return 0, fmt.Errorf("ffprobe failed (%d): %s", result.ExitCode, result.Stderr)
+	}
+	duration, err := strconv.ParseFloat(strings.TrimSpace(result.Stdout), 64)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("video has no duration: %q", result.Stdout)
+	}
+	return duration, nil
+}
+
+func generateAudioPreview(inputPath string, outputPath string, variant previewVariant) error {
+	tempFile, err := os.CreateTemp("", "preview-audio-*.png")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	// prefer the embedded cover art, fall back to a waveform
+	coverCmd := exec.Command("ffmpeg", "-y", "-i", inputPath, "-map", "0:v:0", "-frames:v", "1", tempFile.Name())
+	if err := runPreviewTool(coverCmd); err != nil {
+		if errors.Is(err, ErrFilePreviewToolsMissing) {
+			return err
+		}
+		waveform := fmt.Sprintf("showwavespic=s=%dx%d:colors=white", variant.Width, variant.Height)
+		waveformCmd := exec.Command("ffmpeg", "-y", "-i", inputPath, "-filter_complex", waveform, "-frames:v", "1", tempFile.Name())
+		if err := runPreviewTool(waveformCmd); err != nil {
+			return err
+		}
+	}
+
+	return generateImagePreview(tempFile.Name(), outputPath, variant)
 }
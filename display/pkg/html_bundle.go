@@ -0,0 +1,243 @@
+package pkg
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"plg-mudics/display/browser"
+)
+
+const bundleCacheName = "bundle-cache"
+
+// Limits for extracting bundles, so that a broken or malicious ZIP can not fill the storage.
+const maxBundleSize = 1 << 30
+const maxBundleFiles = 10000
+
+var bundleServer = bundleServerType{}
+
+// bundleServerType serves the opened HTML bundle on a local HTTP origin, so that relative
+// assets, fonts and scripts load like on a web server. Only the folder of the opened bundle
+// is served.
+type bundleServerType struct {
+	once    sync.Once
+	address string
+	err     error
+
+	mutex sync.Mutex
+	root  string
+}
+
+// openBundle extracts a ZIP of a static web page, if it is not extracted yet, and shows its
+// index.html.
+func openBundle(path string) error {
+	root, err := extractBundle(path)
+	if err != nil {
+		return err
+	}
+
+	address, err := bundleServer.start()
+	if err != nil {
+		return err
+	}
+	bundleServer.mutex.Lock()
+	bundleServer.root = root
+	bundleServer.mutex.Unlock()
+
+	return browser.Browser.OpenLocalPage("http://" + address + "/index.html")
+}
+
+// bundleIndexDir returns the folder of the shallowest index.html, as many ZIPs contain the
+// page in a single top level folder.
+func bundleIndexDir(files []*zip.File) (string, bool) {
+	indexDir := ""
+	depth := -1
+	for _, file := range files {
+		if path.Base(file.Name) != "index.html" || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if fileDepth := strings.Count(file.Name, "/"); depth == -1 || fileDepth < depth {
+			indexDir = path.Dir(file.Name)
+			depth = fileDepth
+		}
+	}
+	if indexDir == "." {
+		indexDir = ""
+	}
+	return indexDir, depth != -1
+}
+
+// extractBundle extracts the bundle into the bundle cache and returns the folder that
+// contains index.html.
+func extractBundle(zipPath string) (string, error) {
+	cachePath, err := getCachePath(bundleCacheName)
+	if err != nil {
+		return "", err
+	}
+	source, key, err := cacheSource(zipPath)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid zip file: %w", ErrFileTypeNotSupported, err)
+	}
+	defer archive.Close()
+
+	indexDir, ok := bundleIndexDir(archive.File)
+	if !ok {
+		return "", fmt.Errorf("%w: zip file does not contain an index.html", ErrFileTypeNotSupported)
+	}
+
+	targetDir := filepath.Join(cachePath, key)
+	root := filepath.Join(targetDir, "site", filepath.FromSlash(indexDir))
+	if _, err := os.Stat(filepath.Join(targetDir, "source.json")); err == nil {
+		return root, nil
+	}
+
+	// everything is written into a temporary directory that is renamed when complete
+	tempDir, err := os.MkdirTemp(cachePath, ".extract-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := extractZipFiles(archive.File, filepath.Join(tempDir, "site")); err != nil {
+		return "", err
+	}
+	if err := writeCacheSource(tempDir, source); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tempDir, targetDir); err != nil {
+		// extracted in parallel by another request
+		if _, statErr := os.Stat(filepath.Join(targetDir, "source.json")); statErr == nil {
+			return root, nil
+		}
+		return "", fmt.Errorf("failed to move extracted bundle: %w", err)
+	}
+
+	slog.Info("HTML bundle extracted", "file", zipPath)
+	return root, nil
+}
+
+func extractZipFiles(files []*zip.File, targetDir string) error {
+	if len(files) > maxBundleFiles {
+		return fmt.Errorf("%w: bundle contains more than %d files", ErrFileTypeNotSupported, maxBundleFiles)
+	}
+
+	var remaining int64 = maxBundleSize
+	for _, file := range files {
+		// rejects absolute paths and paths leaving the target directory
+		if !filepath.IsLocal(file.Name) {
+			return fmt.Errorf("%w: unsafe path in bundle: %s", ErrFileTypeNotSupported, file.Name)
+		}
+		target := filepath.Join(targetDir, filepath.FromSlash(file.Name))
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			continue
+		case !mode.IsRegular():
+			// symlinks could point outside of the bundle
+			slog.Warn("Skipping special file in bundle", "name", file.Name)
+			continue
+		}
+
+		written, err := extractZipFile(file, target, remaining)
+		if err != nil {
+			return err
+		}
+		remaining -= written
+	}
+	return nil
+}
+
+func extractZipFile(file *zip.File, target string, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	output, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", file.Name, err)
+	}
+	defer output.Close()
+
+	// the sizes in the zip header can not be trusted
+	written, err := io.Copy(output, io.LimitReader(reader, limit+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract %s: %w", file.Name, err)
+	}
+	if written > limit {
+		return 0, fmt.Errorf("%w: bundle is larger than %d bytes", ErrFileTypeNotSupported, int64(maxBundleSize))
+	}
+	return written, nil
+}
+
+// start listens on a random loopback port on first use and returns the address.
+func (bs *bundleServerType) start() (string, error) {
+	bs.once.Do(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			bs.err = fmt.Errorf("failed to start bundle server: %w", err)
+			return
+		}
+		bs.address = listener.Addr().String()
+
+		go func() {
+			if err := http.Serve(listener, bs); err != nil {
+				slog.Error("Bundle server stopped", "error", err)
+			}
+		}()
+	})
+	return bs.address, bs.err
+}
+
+func (bs *bundleServerType) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bs.mutex.Lock()
+	root := bs.root
+	bs.mutex.Unlock()
+
+	if root == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// bundles are replaced on the same origin, nothing may come from an older one
+	w.Header().Set("Cache-Control", "no-store")
+	http.FileServerFS(noDotFilesFS{os.DirFS(root)}).ServeHTTP(w, r)
+}
+
+// noDotFilesFS hides dot files like .git or .env that are often zipped by accident.
+type noDotFilesFS struct {
+	fs.FS
+}
+
+func (n noDotFilesFS) Open(name string) (fs.File, error) {
+	for part := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(part, ".") && part != "." {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return n.FS.Open(name)
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrIdentifyInvalid = errors.New("invalid identify request")
+
+type identifyConfig struct {
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+	Group  string `json:"group"`
+	// Duration is in milliseconds.
+	Duration int `json:"duration"`
+}
+
+// Identify shows the number, name and group on top of the current content, so that a screen
+// can be matched to its entry in the control server. Opening other content removes it early.
+func Identify(request api.IdentifyRequest) error {
+	if request.Duration == 0 {
+		request.Duration = api.IdentifyDefaultDuration
+	}
+	if request.Duration < 1 || request.Duration > api.IdentifyMaxDuration {
+		return fmt.Errorf("%w: duration has to be between 1 and %d seconds", ErrIdentifyInvalid, api.IdentifyMaxDuration)
+	}
+
+	config, err := json.Marshal(identifyConfig{
+		Number:   request.Number,
+		Name:     request.Name,
+		Group:    request.Group,
+		Duration: request.Duration * 1000,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode identify config: %w", err)
+	}
+	return browser.Browser.RunScript(strings.Replace(identifyScript, "IDENTIFY_CONFIG", string(config), 1))
+}
+
+// identifyScript draws into a shadow root above the overlays, like overlayScript. A second
+// identify replaces the first. IDENTIFY_CONFIG is replaced with the identifyConfig.
+const identifyScript = `(() => {
+	const config = IDENTIFY_CONFIG;
+	if (window.plgMudicsIdentify) {
+		window.plgMudicsIdentify.remove();
+	}
+
+	const host = document.createElement('plg-mudics-identify');
+	host.style.cssText = 'position: fixed; inset: 0; z-index: 2147483647; pointer-events: none;';
+	const root = host.attachShadow({ mode: 'closed' });
+	const style = document.createElement('style');
+	style.textContent = ` + "`" + `
+		.panel { position: absolute; inset: 0; display: flex; flex-direction: column; justify-content: center; align-items: center; gap: 2vh; background: rgb(0 0 0 / 0.8); color: white; font-family: ui-sans-serif, system-ui, sans-serif; text-align: center; outline: 2vh solid oklch(79.5% 0.184 86.047); outline-offset: -2vh; }
+		.number { font-size: 45vh; line-height: 1; font-weight: bold; font-variant-numeric: tabular-nums; }
+		.name { font-size: 8vh; }
+		.group { font-size: 5vh; opacity: 0.8; }
+	` + "`" + `;
+	const panel = document.createElement('div');
+	panel.className = 'panel';
+	for (const [className, text] of [['number', String(config.number)], ['name', config.name], ['group', config.group]]) {
+		if (text) {
+			const line = document.createElement('div');
+			line.className = className;
+			line.textContent = text;
+			panel.append(line);
+		}
+	}
+	root.append(style, panel);
+	document.documentElement.append(host);
+
+	const timer = setTimeout(() => window.plgMudicsIdentify.remove(), config.duration);
+	window.plgMudicsIdentify = {
+		remove() {
+			clearTimeout(timer);
+			host.remove();
+			window.plgMudicsIdentify = null;
+		},
+	};
+})();`
@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+var ErrIdleScreenInvalid = errors.New("invalid idle screen")
+var ErrIdleScreenNotFound = errors.New("idle screen image not found")
+
+var idleScreen = idleScreenType{}
+
+// idleScreenType holds what the display shows after starting. It is stored in the storage
+// directory, so it survives restarts.
+type idleScreenType struct {
+	mutex  sync.Mutex
+	loaded bool
+	screen api.IdleScreen
+}
+
+func getIdleScreenPath() (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	// hidden, so that it does not show up in the file list
+	return filepath.Join(storagePath, ".idle-screen.json"), nil
+}
+
+// GetIdleScreen returns the stored idle screen, the info panel if none is stored.
+func GetIdleScreen() (api.IdleScreen, error) {
+	idleScreen.mutex.Lock()
+	defer idleScreen.mutex.Unlock()
+	return idleScreen.load()
+}
+
+// SetIdleScreen validates and stores the idle screen. It is shown on the next start or with
+// ShowIdleScreen.
+func SetIdleScreen(screen api.IdleScreen) (api.IdleScreen, error) {
+	if screen.Kind == "" {
+		screen.Kind = api.IdleScreenInfo
+	}
+	if err := validateIdleScreen(screen); err != nil {
+		return api.IdleScreen{}, err
+	}
+
+	path, err := getIdleScreenPath()
+	if err != nil {
+		return api.IdleScreen{}, err
+	}
+
+	idleScreen.mutex.Lock()
+	defer idleScreen.mutex.Unlock()
+
+	if err := writeJSONFile(path, screen); err != nil {
+		return api.IdleScreen{}, fmt.Errorf("failed to write idle screen: %w", err)
+	}
+	idleScreen.screen = screen
+	idleScreen.loaded = true
+	return screen, nil
+}
+
+// ShowIdleScreen shows the stored idle screen.
+func ShowIdleScreen() error {
+	screen, err := GetIdleScreen()
+	if err != nil {
+		return err
+	}
+
+	options, err := ResolveDisplayOptions(api.DisplayOptions{})
+	if err != nil {
+		slog.Warn("Failed to read display options, using the defaults", "error", err)
+	}
+	ResetView()
+	applyTransition(options)
+
+	switch screen.Kind {
+	case api.IdleScreenImage:
+		path, exists, err := ResolveStorageFilePath(screen.Image)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: file %s", ErrIdleScreenNotFound, screen.Image)
+		}
+		return openTemplate(imageTemplate(path, options))
+	case api.IdleScreenHTML:
+		return openTemplate(htmlTemplate(screen.HTML))
+	default:
+		return openInfoScreen(screen.ControlURL)
+	}
+}
+
+// openInfoScreen shows the IP and MAC address, and a QR code of controlURL. Without
+// controlURL, the QR code points to a control server running on the display itself.
+func openInfoScreen(controlURL string) error {
+	html := strings.ReplaceAll(shared.RawSplashScreenTemplate, "%%APP-VERSION%%", shared.Version)
+
+	ip, err := getDeviceIp()
+	if err != nil {
+		slog.Error("Failed to get device IP", "error", err)
+	}
+	mac, err := getDeviceMac()
+	if err != nil {
+		slog.Error("Failed to get device MAC address", "error", err)
+	}
+
+	if controlURL == "" && !isPortFree(Config.ControlPort) {
+		controlURL = fmt.Sprintf("http://%s:%d", ip, Config.ControlPort)
+	}
+	qrCodePath := ""
+	if controlURL != "" {
+		qrCodePath, err = generateQRCode(controlURL)
+		if err != nil {
+			slog.Error("could not generate qr code", "error", err)
+		} else {
+			fileHandler.addTempPath(qrCodePath)
+		}
+	}
+
+	return openTemplate(startScreenTemplate(html, ip, mac, qrCodePath))
+}
+
+func (is *idleScreenType) load() (api.IdleScreen, error) {
+	if is.loaded {
+		return is.screen, nil
+	}
+
+	path, err := getIdleScreenPath()
+	if err != nil {
+		return api.IdleScreen{}, err
+	}
+	is.screen = api.IdleScreen{Kind: api.IdleScreenInfo}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		is.loaded = true
+		return is.screen, nil
+	}
+	if err != nil {
+		return api.IdleScreen{}, fmt.Errorf("failed to read idle screen: %w", err)
+	}
+	if err := json.Unmarshal(data, &is.screen); err != nil {
+		return api.IdleScreen{}, fmt.Errorf("failed to parse idle screen: %w", err)
+	}
+
+	is.loaded = true
+	return is.screen, nil
+}
+
+func validateIdleScreen(screen api.IdleScreen) error {
+	if !slices.Contains(screen.Kind.EnumValues(), string(screen.Kind)) {
+		return fmt.Errorf("%w: kind has to be one of %s", ErrIdleScreenInvalid, strings.Join(screen.Kind.EnumValues(), ", "))
+	}
+	if screen.ControlURL != "" {
+		parsed, err := url.Parse(screen.ControlURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: control URL has to be an http or https URL", ErrIdleScreenInvalid)
+		}
+	}
+
+	switch screen.Kind {
+	case api.IdleScreenImage:
+		if screen.Image == "" {
+			return fmt.Errorf("%w: image is required", ErrIdleScreenInvalid)
+		}
+		if _, err := resolveImageURL(screen.Image, ErrIdleScreenInvalid, ErrIdleScreenNotFound); err != nil {
+			return err
+		}
+	case api.IdleScreenHTML:
+		if screen.HTML == "" {
+			return fmt.Errorf("%w: html is required", ErrIdleScreenInvalid)
+		}
+	}
+	return nil
+}
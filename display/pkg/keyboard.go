@@ -1,13 +1,26 @@
 package pkg
 
 import (
+	"errors"
 	"fmt"
-	"log/slog"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/micmonay/keybd_event"
+
+	"plg-mudics/shared/api"
 )
 
+var ErrKeyUnknown = errors.New("unknown key")
+var ErrTypeTextInvalid = errors.New("invalid text")
+var ErrKeyboardBusy = errors.New("the keyboard is busy with a macro or typed text")
+
+// keyboard is held while keys are sent, so that macros, KeyboardInput and TypeText do not
+// mix their keys.
+var keyboard sync.Mutex
+
 type KeyAction int
 
 const (
@@ -20,31 +33,39 @@ type Input struct {
 	Action KeyAction
 }
 
+// KeyKnown reports whether key is a modifier or one of KeyboardEvents.
+func KeyKnown(key string) bool {
+	switch key {
+	case "Shift", "ShiftLeft", "ShiftRight", "Ctrl", "Control", "ControlLeft", "ControlRight",
+		"Alt", "AltLeft", "AltRight", "Super", "Meta", "MetaLeft", "MetaRight":
+		return true
+	}
+	_, ok := KeyboardEvents[key]
+	return ok
+}
+
 func KeyboardInput(inputs []Input) error {
 	var err error
 
+	for _, input := range inputs {
+		if !KeyKnown(input.Key) {
+			return fmt.Errorf("%w: %s", ErrKeyUnknown, input.Key)
+		}
+	}
+
+	if !keyboard.TryLock() {
+		return ErrKeyboardBusy
+	}
+	defer keyboard.Unlock()
+
 	kb, err := keybd_event.NewKeyBonding()
 	if err != nil {
 		return fmt.Errorf("failed to create key bonding: %w", err)
 	}
 
 	for _, input := range inputs {
-		switch input.Key {
-		case "Shift", "ShiftLeft", "ShiftRight":
-			kb.HasSHIFT(true)
-		case "Ctrl", "Control", "ControlLeft", "ControlRight":
-			kb.HasCTRL(true)
-		case "Alt", "AltLeft", "AltRight":
-			kb.HasALT(true)
-		case "Super", "Meta", "MetaLeft", "MetaRight":
-			kb.HasSuper(true)
-		default:
-			keyCode, ok := KeyboardEvents[input.Key]
-			if !ok {
-				slog.Warn("Could not parse keyboard input", "key", input.Key)
-				continue
-			}
-			kb.SetKeys(keyCode)
+		if !setModifier(&kb, input.Key) {
+			kb.SetKeys(KeyboardEvents[input.Key])
 		}
 
 		switch input.Action {
@@ -64,6 +85,85 @@ func KeyboardInput(inputs []Input) error {
 	return nil
 }
 
+// setModifier holds key on kb if it is a modifier and reports whether it is one. AltRight is
+// AltGr, like for TypeText.
+func setModifier(kb *keybd_event.KeyBonding, key string) bool {
+	switch key {
+	case "Shift", "ShiftLeft":
+		kb.HasSHIFT(true)
+	case "ShiftRight":
+		kb.HasSHIFTR(true)
+	case "Ctrl", "Control", "ControlLeft":
+		kb.HasCTRL(true)
+	case "ControlRight":
+		kb.HasCTRLR(true)
+	case "Alt", "AltLeft":
+		kb.HasALT(true)
+	case "AltRight":
+		kb.HasALTGR(true)
+	case "Super", "Meta", "MetaLeft", "MetaRight":
+		kb.HasSuper(true)
+	default:
+		return false
+	}
+	return true
+}
+
+// TypeText types text with the keyboard layout of the display, so that e.g. z and y or umlauts
+// come out right on German displays. delay is the pause between two characters. Nothing is
+// typed if the layout has no keys for a character.
+func TypeText(text string, delay time.Duration) error {
+	if len([]rune(text)) > api.TypeTextMaxLength {
+		return fmt.Errorf("%w: text is longer than %d characters", ErrTypeTextInvalid, api.TypeTextMaxLength)
+	}
+	if delay < 0 || delay > api.TypeTextMaxDelay*time.Millisecond {
+		return fmt.Errorf("%w: delay has to be between 0 and %d milliseconds", ErrTypeTextInvalid, api.TypeTextMaxDelay)
+	}
+
+	name, layout, err := activeKeyboardLayout()
+	if err != nil {
+		return err
+	}
+	// windows line breaks would be typed twice
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var missing []string
+	for _, character := range text {
+		if _, ok := layout[character]; !ok && !slices.Contains(missing, string(character)) {
+			missing = append(missing, string(character))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: the %s keyboard layout can not type %s", ErrTypeTextInvalid, name, strings.Join(missing, " "))
+	}
+	if !keyboard.TryLock() {
+		return ErrKeyboardBusy
+	}
+	defer keyboard.Unlock()
+
+	kb, err := keybd_event.NewKeyBonding()
+	if err != nil {
+		return fmt.Errorf("failed to create key bonding: %w", err)
+	}
+	for _, character := range text {
+		for _, stroke := range layout[character] {
+			kb.Clear()
+			kb.HasSHIFT(stroke.Shift)
+			kb.HasALTGR(stroke.AltGr)
+			kb.SetKeys(KeyboardEvents[stroke.Key])
+			if err := kb.Press(); err != nil {
+				return fmt.Errorf("failed to run key event: %w", err)
+			}
+			time.Sleep(time.Microsecond * 10)
+			if err := kb.Release(); err != nil {
+				return fmt.Errorf("failed to run key event: %w", err)
+			}
+			time.Sleep(time.Microsecond * 10)
+		}
+		time.Sleep(delay)
+	}
+	return nil
+}
+
 var KeyboardEvents = map[string]int{
 	"Escape":         keybd_event.VK_ESC,
 	"Digit1":         keybd_event.VK_1,
@@ -0,0 +1,198 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var ErrKeyboardLayoutUnsupported = errors.New("keyboard layout not supported")
+
+// keyStroke is a key of KeyboardEvents with the modifiers held while pressing it.
+type keyStroke struct {
+	Key   string
+	Shift bool
+	AltGr bool
+}
+
+// keyboardLayout maps characters to the strokes that type them, more than one for characters
+// composed with dead keys.
+type keyboardLayout map[rune][]keyStroke
+
+var detectedLayout struct {
+	once sync.Once
+	name string
+}
+
+// activeKeyboardLayout returns the layout of Config.KeyboardLayout, else the one of the X
+// session, in XKB notation like de(nodeadkeys).
+func activeKeyboardLayout() (string, keyboardLayout, error) {
+	name := Config.KeyboardLayout
+	if name == "" {
+		detectedLayout.once.Do(func() {
+			detectedLayout.name = detectKeyboardLayout()
+		})
+		name = detectedLayout.name
+	}
+
+	base, variant, _ := strings.Cut(strings.TrimSuffix(name, ")"), "(")
+	switch {
+	case base == "us" && variant == "":
+		return name, usKeyboardLayout(), nil
+	case base == "de" && (variant == "" || variant == "nodeadkeys"):
+		return name, deKeyboardLayout(variant == ""), nil
+	default:
+		return name, nil, fmt.Errorf("%w: %s, use us, de or de(nodeadkeys)", ErrKeyboardLayoutUnsupported, name)
+	}
+}
+
+// detectKeyboardLayout asks setxkbmap for the first layout of the session, us if that fails.
+func detectKeyboardLayout() string {
+	output, err := exec.Command("setxkbmap", "-query").Output()
+	if err != nil {
+		slog.Warn("Failed to detect keyboard layout, using us", "error", err)
+		return "us"
+	}
+
+	layout, variant := "", ""
+	for line := range strings.Lines(string(output)) {
+		key, value, _ := strings.Cut(line, ":")
+		// only the first of several layouts is active without switching
+		value, _, _ = strings.Cut(strings.TrimSpace(value), ",")
+		switch strings.TrimSpace(key) {
+		case "layout":
+			layout = value
+		case "variant":
+			variant = value
+		}
+	}
+	if layout == "" {
+		slog.Warn("Failed to detect keyboard layout, using us", "output", string(output))
+		return "us"
+	}
+	if variant != "" {
+		layout += "(" + variant + ")"
+	}
+	slog.Info("Keyboard layout detected", "layout", layout)
+	return layout
+}
+
+// addLetters adds a to z and A to Z, with letters swapped as listed in swap.
+func (l keyboardLayout) addLetters(swap map[rune]rune) {
+	for letter := 'a'; letter <= 'z'; letter++ {
+		key := letter
+		if swapped, ok := swap[letter]; ok {
+			key = swapped
+		}
+		code := "Key" + strings.ToUpper(string(key))
+		l[letter] = []keyStroke{{Key: code}}
+		l[letter-'a'+'A'] = []keyStroke{{Key: code, Shift: true}}
+	}
+}
+
+// addKeys adds the characters of a key without, with shift and with AltGr, " " for none.
+func (l keyboardLayout) addKeys(keys map[string]string) {
+	for key, characters := range keys {
+		for i, character := range []rune(characters) {
+			if character != ' ' {
+				l[character] = []keyStroke{{Key: key, Shift: i == 1, AltGr: i == 2}}
+			}
+		}
+	}
+}
+
+func (l keyboardLayout) addWhitespace() {
+	l[' '] = []keyStroke{{Key: "Space"}}
+	l['\t'] = []keyStroke{{Key: "Tab"}}
+	l['\n'] = []keyStroke{{Key: "Enter"}}
+}
+
+func usKeyboardLayout() keyboardLayout {
+	layout := keyboardLayout{}
+	layout.addLetters(nil)
+	layout.addWhitespace()
+	layout.addKeys(map[string]string{
+		"Backquote":    "`~",
+		"Digit1":       "1!",
+		"Digit2":       "2@",
+		"Digit3":       "3#",
+		"Digit4":       "4$",
+		"Digit5":       "5%",
+		"Digit6":       "6^",
+		"Digit7":       "7&",
+		"Digit8":       "8*",
+		"Digit9":       "9(",
+		"Digit0":       "0)",
+		"Minus":        "-_",
+		"Equal":        "=+",
+		"BracketLeft":  "[{",
+		"BracketRight": "]}",
+		"Backslash":    `\|`,
+		"Semicolon":    ";:",
+		"Quote":        `'"`,
+		"Comma":        ",<",
+		"Period":       ".>",
+		"Slash":        "/?",
+	})
+	return layout
+}
+
+// deadKeyCompositions are the characters a dead key composes with the following letter.
+var deadKeyCompositions = map[rune]string{
+	'^': "aâeêiîoôuûAÂEÊIÎOÔUÛ",
+	'´': "aáeéiíoóuúyýAÁEÉIÍOÓUÚYÝ",
+	'`': "aàeèiìoòuùAÀEÈIÌOÒUÙ",
+}
+
+// deKeyboardLayout is the German layout. With deadKeys, ^, ´ and ` do not type anything by
+// themselves, but compose with the next key, e.g. ´ and e is é. ~ on AltGr and + is a plain
+// key in the basic layout, so ñ, ã and õ can not be typed.
+func deKeyboardLayout(deadKeys bool) keyboardLayout {
+	layout := keyboardLayout{}
+	layout.addLetters(map[rune]rune{'y': 'z', 'z': 'y'})
+	layout.addWhitespace()
+	layout.addKeys(map[string]string{
+		"Backquote":     "^°",
+		"Digit1":        "1!",
+		"Digit2":        "2\"²",
+		"Digit3":        "3§³",
+		"Digit4":        "4$",
+		"Digit5":        "5%",
+		"Digit6":        "6&",
+		"Digit7":        "7/{",
+		"Digit8":        "8([",
+		"Digit9":        "9)]",
+		"Digit0":        "0=}",
+		"Minus":         `ß?\`,
+		"Equal":         "´`",
+		"BracketLeft":   "üÜ",
+		"BracketRight":  "+*~",
+		"Semicolon":     "öÖ",
+		"Quote":         "äÄ",
+		"Backslash":     "#'",
+		"Comma":         ",;",
+		"Period":        ".:",
+		"Slash":         "-_",
+		"IntlBackslash": "<>|",
+		"KeyQ":          "  @",
+		"KeyE":          "  €",
+		"KeyM":          "  µ",
+	})
+	if !deadKeys {
+		return layout
+	}
+
+	for deadKey, compositions := range deadKeyCompositions {
+		stroke := layout[deadKey][0]
+		// a dead key followed by space types the key itself
+		layout[deadKey] = []keyStroke{stroke, {Key: "Space"}}
+		characters := []rune(compositions)
+		for i := 0; i < len(characters); i += 2 {
+			layout[characters[i+1]] = append([]keyStroke{stroke}, layout[characters[i]]...)
+		}
+	}
+	return layout
+}
@@ -0,0 +1,369 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrLayoutInvalid = errors.New("invalid layout")
+var ErrLayoutNotFound = errors.New("layout not found")
+var ErrLayoutFileNotFound = errors.New("file of a layout item not found")
+
+var layouts = layoutsType{}
+
+// layoutsType remembers which player item shows the layout, so that changed zones can be
+// sent to it instead of reloading the whole layout.
+type layoutsType struct {
+	mutex    sync.Mutex
+	shownURL string
+}
+
+var zoneName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Kinds of layoutItem.
+const (
+	layoutItemImage = "image"
+	layoutItemVideo = "video"
+	// layoutItemFrame is shown in an iframe: PDFs, HTML and websites.
+	layoutItemFrame = "frame"
+)
+
+// layoutZone is a zone as the layout page needs it, with the items resolved to URLs.
+type layoutZone struct {
+	Name   string         `json:"name"`
+	X      float64        `json:"x"`
+	Y      float64        `json:"y"`
+	Width  float64        `json:"width"`
+	Height float64        `json:"height"`
+	Fit    api.DisplayFit `json:"fit"`
+	Items  []layoutItem   `json:"items"`
+}
+
+type layoutItem struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	// Duration is in milliseconds, 0 plays a video until it ends.
+	Duration int `json:"duration"`
+}
+
+func getLayoutPath() (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	// hidden, so that it does not show up in the file list
+	return filepath.Join(storagePath, ".layout.json"), nil
+}
+
+// GetLayout returns the stored layout, without zones if none is stored.
+func GetLayout() (api.Layout, error) {
+	layouts.mutex.Lock()
+	defer layouts.mutex.Unlock()
+	return readLayout()
+}
+
+func readLayout() (api.Layout, error) {
+	path, err := getLayoutPath()
+	if err != nil {
+		return api.Layout{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return api.Layout{Zones: []api.Zone{}}, nil
+	}
+	if err != nil {
+		return api.Layout{}, fmt.Errorf("failed to read layout: %w", err)
+	}
+
+	var layout api.Layout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return api.Layout{}, fmt.Errorf("failed to parse layout: %w", err)
+	}
+	return layout, nil
+}
+
+func writeLayout(layout api.Layout) error {
+	path, err := getLayoutPath()
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(path, layout); err != nil {
+		return fmt.Errorf("failed to write layout: %w", err)
+	}
+	return nil
+}
+
+// SetLayout validates, stores and shows a layout.
+func SetLayout(layout api.Layout) error {
+	if err := validateLayout(layout); err != nil {
+		return err
+	}
+
+	layouts.mutex.Lock()
+	defer layouts.mutex.Unlock()
+
+	if err := writeLayout(layout); err != nil {
+		return err
+	}
+	return layouts.show(layout)
+}
+
+// ShowLayout shows the stored layout.
+func ShowLayout() error {
+	layouts.mutex.Lock()
+	defer layouts.mutex.Unlock()
+
+	layout, err := readLayout()
+	if err != nil {
+		return err
+	}
+	if len(layout.Zones) == 0 {
+		return fmt.Errorf("%w: no layout is stored", ErrLayoutNotFound)
+	}
+	return layouts.show(layout)
+}
+
+// DeleteLayout removes the stored layout. A shown layout stays until other content is opened.
+func DeleteLayout() error {
+	layouts.mutex.Lock()
+	defer layouts.mutex.Unlock()
+
+	path, err := getLayoutPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove layout: %w", err)
+	}
+	return nil
+}
+
+// SetZone replaces the items of a zone of the stored layout. If the layout is shown, only the
+// zone is changed on the screen.
+func SetZone(name string, request api.ZoneRequest) (api.Zone, error) {
+	layouts.mutex.Lock()
+	defer layouts.mutex.Unlock()
+
+	layout, err := readLayout()
+	if err != nil {
+		return api.Zone{}, err
+	}
+	index := slices.IndexFunc(layout.Zones, func(zone api.Zone) bool { return zone.Name == name })
+	if index == -1 {
+		return api.Zone{}, fmt.Errorf("%w: zone %s", ErrLayoutNotFound, name)
+	}
+
+	zone := layout.Zones[index]
+	zone.Fit = request.Fit
+	zone.Items = request.Items
+	if err := validateZone(zone); err != nil {
+		return api.Zone{}, err
+	}
+	layout.Zones[index] = zone
+	if err := writeLayout(layout); err != nil {
+		return api.Zone{}, err
+	}
+
+	if layouts.shownURL == "" || browser.Browser.ShownURL() != layouts.shownURL {
+		return zone, nil
+	}
+	resolved, err := resolveZone(zone)
+	if err != nil {
+		return api.Zone{}, err
+	}
+	if err := browser.Browser.PostMessage(map[string]any{"type": "zone", "zone": resolved}); err != nil {
+		return api.Zone{}, err
+	}
+	return zone, nil
+}
+
+func (l *layoutsType) show(layout api.Layout) error {
+	options, err := ResolveDisplayOptions(api.DisplayOptions{})
+	if err != nil {
+		return err
+	}
+	ResetView()
+	applyTransition(options)
+
+	zones := make([]layoutZone, 0, len(layout.Zones))
+	for _, zone := range layout.Zones {
+		resolved, err := resolveZone(zone)
+		if err != nil {
+			return err
+		}
+		zones = append(zones, resolved)
+	}
+
+	var templateBuffer bytes.Buffer
+	if err := layoutTemplate(zones).Render(context.Background(), &templateBuffer); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if err := browser.Browser.OpenHTML(templateBuffer.String()); err != nil {
+		return err
+	}
+	l.shownURL = browser.Browser.ShownURL()
+	return nil
+}
+
+func validateLayout(layout api.Layout) error {
+	if len(layout.Zones) == 0 || len(layout.Zones) > api.LayoutMaxZones {
+		return fmt.Errorf("%w: a layout has 1 to %d zones", ErrLayoutInvalid, api.LayoutMaxZones)
+	}
+
+	names := map[string]bool{}
+	for _, zone := range layout.Zones {
+		if names[zone.Name] {
+			return fmt.Errorf("%w: zone %s exists twice", ErrLayoutInvalid, zone.Name)
+		}
+		names[zone.Name] = true
+
+		if err := validateZone(zone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateZone(zone api.Zone) error {
+	switch {
+	case !zoneName.MatchString(zone.Name):
+		return fmt.Errorf("%w: zone names may only contain letters, digits, - and _", ErrLayoutInvalid)
+	case zone.X < 0 || zone.Y < 0 || zone.Width <= 0 || zone.Height <= 0 || zone.X+zone.Width > 100 || zone.Y+zone.Height > 100:
+		return fmt.Errorf("%w: zone %s is not inside the screen", ErrLayoutInvalid, zone.Name)
+	case zone.Fit != "" && !slices.Contains(zone.Fit.EnumValues(), string(zone.Fit)):
+		return fmt.Errorf("%w: fit of zone %s has to be one of %s", ErrLayoutInvalid, zone.Name, strings.Join(zone.Fit.EnumValues(), ", "))
+	case len(zone.Items) > api.LayoutMaxItems:
+		return fmt.Errorf("%w: zone %s has more than %d items", ErrLayoutInvalid, zone.Name, api.LayoutMaxItems)
+	}
+
+	for _, item := range zone.Items {
+		if item.Duration < 0 {
+			return fmt.Errorf("%w: duration in zone %s is negative", ErrLayoutInvalid, zone.Name)
+		}
+		if _, _, err := zoneItemSource(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zoneItemSource checks an item and returns its kind and URL. HTML has no URL yet, it is
+// written to a file when the item is shown.
+func zoneItemSource(item api.ZoneItem) (string, string, error) {
+	set := 0
+	for _, value := range []string{item.File, item.HTML, item.URL} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return "", "", fmt.Errorf("%w: an item has exactly one of file, html and url", ErrLayoutInvalid)
+	}
+
+	switch {
+	case item.HTML != "":
+		return layoutItemFrame, "", nil
+	case item.URL != "":
+		parsed, err := url.Parse(item.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return "", "", fmt.Errorf("%w: %s is no http or https URL", ErrLayoutInvalid, item.URL)
+		}
+		if !websiteAllowed(item.URL) {
+			return "", "", fmt.Errorf("%w: %s", ErrWebsiteBlocked, parsed.Host)
+		}
+		return layoutItemFrame, item.URL, nil
+	}
+
+	path, exists, err := ResolveStorageFilePath(item.File)
+	if err != nil {
+		return "", "", err
+	}
+	if !exists {
+		return "", "", fmt.Errorf("%w: %s", ErrLayoutFileNotFound, item.File)
+	}
+	mType, err := mimetype.DetectFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to detect mime type: %w", err)
+	}
+
+	switch {
+	case isAnyType(mType, browserImageTypes):
+		return layoutItemImage, "file://" + path, nil
+	case isAnyType(mType, browserVideoTypes):
+		return layoutItemVideo, "file://" + path, nil
+	case mType.Is("application/pdf"):
+		return layoutItemFrame, "file://" + path + "#toolbar=0&view=Fit", nil
+	default:
+		return "", "", fmt.Errorf("%w: %s can not be shown in a zone", ErrFileTypeNotSupported, mType.String())
+	}
+}
+
+func resolveZone(zone api.Zone) (layoutZone, error) {
+	resolved := layoutZone{
+		Name:   zone.Name,
+		X:      zone.X,
+		Y:      zone.Y,
+		Width:  zone.Width,
+		Height: zone.Height,
+		Fit:    zone.Fit,
+		Items:  []layoutItem{},
+	}
+	if resolved.Fit == "" {
+		resolved.Fit = api.DisplayFitContain
+	}
+
+	for _, item := range zone.Items {
+		kind, source, err := zoneItemSource(item)
+		if err != nil {
+			return layoutZone{}, err
+		}
+		if item.HTML != "" {
+			source, err = writeZoneHTML(item.HTML)
+			if err != nil {
+				return layoutZone{}, err
+			}
+		}
+
+		duration := item.Duration * 1000
+		if duration == 0 && kind != layoutItemVideo {
+			duration = api.LayoutDefaultItemDuration * 1000
+		}
+		resolved.Items = append(resolved.Items, layoutItem{Kind: kind, Source: source, Duration: duration})
+	}
+	return resolved, nil
+}
+
+// writeZoneHTML writes HTML of a zone into a file, which is removed when other content is opened.
+func writeZoneHTML(html string) (string, error) {
+	var templateBuffer bytes.Buffer
+	if err := htmlTemplate(html).Render(context.Background(), &templateBuffer); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp("", "mudics-zone-*.html")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tempFile.Close()
+	fileHandler.addTempPath(tempFile.Name())
+
+	if _, err := tempFile.Write(templateBuffer.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return "file://" + tempFile.Name(), nil
+}
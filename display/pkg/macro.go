@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/micmonay/keybd_event"
+
+	"plg-mudics/shared/api"
+)
+
+var ErrMacroInvalid = errors.New("invalid macro")
+var ErrMacroNotFound = errors.New("macro not found")
+
+var macros = macrosType{}
+
+// macrosType holds the stored macros. They are stored in the storage directory, so they
+// survive restarts.
+type macrosType struct {
+	mutex  sync.Mutex
+	loaded bool
+	stored map[string]api.Macro
+}
+
+func getMacrosPath() (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	// hidden, so that it does not show up in the file list
+	return filepath.Join(storagePath, ".macros.json"), nil
+}
+
+// GetMacros returns the stored macros sorted by name.
+func GetMacros() (api.MacrosResponse, error) {
+	macros.mutex.Lock()
+	defer macros.mutex.Unlock()
+
+	if err := macros.load(); err != nil {
+		return api.MacrosResponse{}, err
+	}
+	response := api.MacrosResponse{Macros: []api.Macro{}}
+	for _, macro := range macros.stored {
+		response.Macros = append(response.Macros, macro)
+	}
+	slices.SortFunc(response.Macros, func(a, b api.Macro) int { return strings.Compare(a.Name, b.Name) })
+	return response, nil
+}
+
+// SetMacro validates and stores a macro.
+func SetMacro(macro api.Macro) (api.Macro, error) {
+	if err := validateMacroName(macro.Name); err != nil {
+		return api.Macro{}, err
+	}
+	macro.Steps = normalizeMacroSteps(macro.Steps)
+	if err := validateMacroSteps(macro.Steps); err != nil {
+		return api.Macro{}, err
+	}
+
+	macros.mutex.Lock()
+	defer macros.mutex.Unlock()
+
+	if err := macros.load(); err != nil {
+		return api.Macro{}, err
+	}
+	stored := make(map[string]api.Macro, len(macros.stored)+1)
+	for name, existing := range macros.stored {
+		stored[name] = existing
+	}
+	stored[macro.Name] = macro
+	if err := macros.write(stored); err != nil {
+		return api.Macro{}, err
+	}
+	return macro, nil
+}
+
+// DeleteMacro removes a stored macro.
+func DeleteMacro(name string) error {
+	macros.mutex.Lock()
+	defer macros.mutex.Unlock()
+
+	if err := macros.load(); err != nil {
+		return err
+	}
+	if _, exists := macros.stored[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrMacroNotFound, name)
+	}
+	stored := map[string]api.Macro{}
+	for existingName, existing := range macros.stored {
+		if existingName != name {
+			stored[existingName] = existing
+		}
+	}
+	return macros.write(stored)
+}
+
+// RunStoredMacro runs the macro stored with name.
+func RunStoredMacro(name string) error {
+	macros.mutex.Lock()
+	err := macros.load()
+	macro, exists := macros.stored[name]
+	macros.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrMacroNotFound, name)
+	}
+	return RunMacro(macro.Steps)
+}
+
+// RunMacro runs steps without storing them. It returns once all steps ran, keys that are
+// still pressed then are released, so that no key stays stuck.
+func RunMacro(steps []api.MacroStep) error {
+	steps = normalizeMacroSteps(steps)
+	if err := validateMacroSteps(steps); err != nil {
+		return err
+	}
+	if !keyboard.TryLock() {
+		return ErrKeyboardBusy
+	}
+	defer keyboard.Unlock()
+
+	kb, err := keybd_event.NewKeyBonding()
+	if err != nil {
+		return fmt.Errorf("failed to create key bonding: %w", err)
+	}
+
+	var pressed []string
+	defer func() {
+		if len(pressed) > 0 {
+			bondKeys(&kb, pressed)
+			kb.Release()
+		}
+	}()
+
+	for _, step := range steps {
+		for range step.Repeat {
+			bondKeys(&kb, step.Keys)
+			if step.Action != api.MacroRelease {
+				if err := kb.Press(); err != nil {
+					return fmt.Errorf("failed to run key event: %w", err)
+				}
+				for _, key := range step.Keys {
+					if !slices.Contains(pressed, key) {
+						pressed = append(pressed, key)
+					}
+				}
+			}
+			if step.Action == api.MacroTap {
+				time.Sleep(max(time.Duration(step.Hold)*time.Millisecond, time.Microsecond*10))
+			}
+			if step.Action != api.MacroPress {
+				if err := kb.Release(); err != nil {
+					return fmt.Errorf("failed to run key event: %w", err)
+				}
+				pressed = slices.DeleteFunc(pressed, func(key string) bool { return slices.Contains(step.Keys, key) })
+			}
+			time.Sleep(max(time.Duration(step.Delay)*time.Millisecond, time.Microsecond*10))
+		}
+	}
+	return nil
+}
+
+// bondKeys sets the keys of kb to a chord of KeyboardEvents and modifiers.
+func bondKeys(kb *keybd_event.KeyBonding, keys []string) {
+	kb.Clear()
+	for _, key := range keys {
+		if !setModifier(kb, key) {
+			kb.AddKey(KeyboardEvents[key])
+		}
+	}
+}
+
+func normalizeMacroSteps(steps []api.MacroStep) []api.MacroStep {
+	normalized := slices.Clone(steps)
+	for i := range normalized {
+		if normalized[i].Action == "" {
+			normalized[i].Action = api.MacroTap
+		}
+		if normalized[i].Repeat == 0 {
+			normalized[i].Repeat = 1
+		}
+	}
+	return normalized
+}
+
+func validateMacroName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%w: name is required", ErrMacroInvalid)
+	case len([]rune(name)) > api.MacroMaxNameLength:
+		return fmt.Errorf("%w: name is longer than %d characters", ErrMacroInvalid, api.MacroMaxNameLength)
+	case strings.ContainsFunc(name, unicode.IsControl) || strings.Contains(name, "/"):
+		return fmt.Errorf("%w: name may not contain control characters or /", ErrMacroInvalid)
+	}
+	return nil
+}
+
+func validateMacroSteps(steps []api.MacroStep) error {
+	if len(steps) == 0 || len(steps) > api.MacroMaxSteps {
+		return fmt.Errorf("%w: a macro needs 1 to %d steps", ErrMacroInvalid, api.MacroMaxSteps)
+	}
+
+	duration := 0
+	for i, step := range steps {
+		switch {
+		case !slices.Contains(step.Action.EnumValues(), string(step.Action)):
+			return fmt.Errorf("%w: step %d: action has to be one of %s", ErrMacroInvalid, i+1, strings.Join(step.Action.EnumValues(), ", "))
+		case len(step.Keys) == 0 || len(step.Keys) > api.MacroMaxKeys:
+			return fmt.Errorf("%w: step %d: a step needs 1 to %d keys", ErrMacroInvalid, i+1, api.MacroMaxKeys)
+		case step.Hold < 0 || step.Hold > api.MacroMaxStepTime || step.Delay < 0 || step.Delay > api.MacroMaxStepTime:
+			return fmt.Errorf("%w: step %d: hold and delay have to be between 0 and %d milliseconds", ErrMacroInvalid, i+1, api.MacroMaxStepTime)
+		case step.Hold > 0 && step.Action != api.MacroTap:
+			return fmt.Errorf("%w: step %d: only tap holds keys", ErrMacroInvalid, i+1)
+		case step.Repeat < 1 || step.Repeat > api.MacroMaxRepeat:
+			return fmt.Errorf("%w: step %d: repeat has to be between 1 and %d", ErrMacroInvalid, i+1, api.MacroMaxRepeat)
+		}
+		for _, key := range step.Keys {
+			if !KeyKnown(key) {
+				return fmt.Errorf("%w: step %d: %w: %s", ErrMacroInvalid, i+1, ErrKeyUnknown, key)
+			}
+		}
+		duration += (step.Hold + step.Delay) * step.Repeat
+	}
+	if duration > api.MacroMaxDuration {
+		return fmt.Errorf("%w: the macro takes longer than %d milliseconds", ErrMacroInvalid, api.MacroMaxDuration)
+	}
+	return nil
+}
+
+func (m *macrosType) load() error {
+	if m.loaded {
+		return nil
+	}
+
+	path, err := getMacrosPath()
+	if err != nil {
+		return err
+	}
+	m.stored = map[string]api.Macro{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		m.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read macros: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.stored); err != nil {
+		return fmt.Errorf("failed to parse macros: %w", err)
+	}
+	if m.stored == nil {
+		m.stored = map[string]api.Macro{}
+	}
+
+	m.loaded = true
+	return nil
+}
+
+// write stores the macros and only keeps them when that worked.
+func (m *macrosType) write(stored map[string]api.Macro) error {
+	path, err := getMacrosPath()
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(path, stored); err != nil {
+		return fmt.Errorf("failed to write macros: %w", err)
+	}
+	m.stored = stored
+	return nil
+}
@@ -3,6 +3,7 @@ package pkg
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -14,6 +15,7 @@ import (
 
 	"plg-mudics/display/browser"
 	"plg-mudics/shared"
+	"plg-mudics/shared/api"
 )
 
 func TakeScreenshot() (string, error) {
@@ -34,13 +36,15 @@ func TakeScreenshot() (string, error) {
 }
 
 func GetStoragePath() (string, error) {
-	var storagePath string
+	storagePath := Config.StorageDir
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("unable to determine user home directory: %w", err)
+	if storagePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to determine user home directory: %w", err)
+		}
+		storagePath = filepath.Join(home, ".local", "share", "plg-mudics", "display")
 	}
-	storagePath = filepath.Join(home, ".local", "share", "plg-mudics", "display")
 	if err := os.MkdirAll(storagePath, os.ModePerm); err != nil {
 		return "", fmt.Errorf("failed to create local config directory: %w", err)
 	}
@@ -48,43 +52,89 @@ func GetStoragePath() (string, error) {
 	return storagePath, nil
 }
 
+// writeJSONFile writes next to the target and renames, so a crash never leaves a half
+// written file.
+func writeJSONFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+var ErrPathInvalid = errors.New("invalid file path")
+var ErrPathIsDirectory = errors.New("path is a directory")
+var ErrPathIsNoDirectory = errors.New("path is not a directory")
+
 // ResolveStorageFilePath validates and resolves a storage-relative file path.
 // Returns the full path, whether the file exists, or an error.
 func ResolveStorageFilePath(pathParam string) (string, bool, error) {
+	fullPath, info, err := resolveStoragePath(pathParam)
+	if err != nil || info == nil {
+		return fullPath, false, err
+	}
+
+	if info.IsDir() {
+		return "", false, ErrPathIsDirectory
+	}
+
+	return fullPath, true, nil
+}
+
+// ResolveStorageDirPath is the same as ResolveStorageFilePath, but for directories.
+func ResolveStorageDirPath(pathParam string) (string, bool, error) {
+	fullPath, info, err := resolveStoragePath(pathParam)
+	if err != nil || info == nil {
+		return fullPath, false, err
+	}
+
+	if !info.IsDir() {
+		return "", false, ErrPathIsNoDirectory
+	}
+
+	return fullPath, true, nil
+}
+
+func resolveStoragePath(pathParam string) (string, os.FileInfo, error) {
 	storagePath, err := GetStoragePath()
 	if err != nil {
-		return "", false, fmt.Errorf("failed to get storage path: %w", err)
+		return "", nil, fmt.Errorf("failed to get storage path: %w", err)
 	}
 	cleanPath := filepath.Clean(pathParam)
 	fullPath := filepath.Join(storagePath, cleanPath)
 	rel, err := filepath.Rel(storagePath, fullPath)
 
 	if err != nil || strings.HasPrefix(rel, "..") {
-		return "", false, fmt.Errorf("invalid file path")
+		return "", nil, ErrPathInvalid
 	}
 
 	info, statErr := os.Stat(fullPath)
 
 	if statErr != nil {
 		if os.IsNotExist(statErr) {
-			return fullPath, false, nil
+			return fullPath, nil, nil
 		}
-		return "", false, fmt.Errorf("failed to stat path: %w", statErr)
+		return "", nil, fmt.Errorf("failed to stat path: %w", statErr)
 	}
 
-	if info.IsDir() {
-		return "", false, fmt.Errorf("path is a directory")
-	}
-
-	return fullPath, true, nil
+	return fullPath, info, nil
 }
 
 func ShowHTML(html string) error {
+	options, err := ResolveDisplayOptions(api.DisplayOptions{})
+	if err != nil {
+		slog.Warn("Failed to read display options, using the defaults", "error", err)
+	}
+
 	ResetView()
+	applyTransition(options)
 
 	var templateBuffer bytes.Buffer
-	htmlTemplate(html).Render(context.Background(), &templateBuffer)
-	err := browser.Browser.OpenHTML(templateBuffer.String())
+	htmlSlideTemplate(html).Render(context.Background(), &templateBuffer)
+	err = browser.Browser.OpenHTML(templateBuffer.String())
 
 	return err
 }
@@ -94,4 +144,5 @@ func ResetView() {
 	if err != nil {
 		slog.Error("Failed to close running program", "error", err)
 	}
+	fileHandler.removeTempFiles()
 }
@@ -0,0 +1,174 @@
+package pkg
+
+import (
+	"html"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// renderMarkdown converts the common subset of Markdown to HTML: headings, paragraphs,
+// lists, block quotes, code, rules, emphasis, links and images. Everything else is shown
+// as text. Relative image paths are resolved against baseDir.
+func renderMarkdown(source string, baseDir string) string {
+	var out strings.Builder
+	var paragraph []string
+	var listTag string
+	inCode := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderInlineMarkdown(strings.Join(paragraph, " "), baseDir) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+
+	for line := range strings.Lines(strings.ReplaceAll(source, "\r\n", "\n")) {
+		line = strings.TrimRight(line, "\n")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				out.WriteString("</code></pre>\n")
+			} else {
+				flushParagraph()
+				closeList()
+				out.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		if match := markdownListItem.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			tag := "ul"
+			if strings.HasSuffix(match[1], ".") {
+				tag = "ol"
+			}
+			if tag != listTag {
+				closeList()
+				out.WriteString("<" + tag + ">\n")
+				listTag = tag
+			}
+			out.WriteString("<li>" + renderInlineMarkdown(match[2], baseDir) + "</li>\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case markdownRule.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			out.WriteString("<hr/>\n")
+		case markdownHeading.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(match[1]))
+			out.WriteString("<h" + level + ">" + renderInlineMarkdown(match[2], baseDir) + "</h" + level + ">\n")
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			out.WriteString("<blockquote>" + renderInlineMarkdown(strings.TrimSpace(strings.TrimPrefix(trimmed, ">")), baseDir) + "</blockquote>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flushParagraph()
+	closeList()
+	if inCode {
+		out.WriteString("</code></pre>\n")
+	}
+	return out.String()
+}
+
+var markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+var markdownRule = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+var markdownListItem = regexp.MustCompile(`^([-*+]|\d+\.)\s+(.*)$`)
+
+// markdownLinkOrImage matches an image in groups 1 and 2 or a link in groups 3 and 4.
+var markdownLinkOrImage = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)|\[([^\]]+)\]\(([^)\s]+)\)`)
+var markdownStrong = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+var markdownEmphasis = regexp.MustCompile(`\*(.+?)\*|\b_(.+?)_\b`)
+
+// markdownSchemes are the URL schemes links and images may use, besides relative paths.
+var markdownSchemes = []string{"http", "https", "file"}
+
+// renderInlineMarkdown escapes the text and applies the inline formatting. Code spans are
+// kept as they are. Emphasis only applies to text, never to the attributes of links and
+// images, and links and images with other schemes than markdownSchemes are left out.
+func renderInlineMarkdown(text string, baseDir string) string {
+	var out strings.Builder
+	for i, part := range strings.Split(text, "`") {
+		if i%2 == 1 {
+			out.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+
+		part = html.EscapeString(part)
+		last := 0
+		for _, match := range markdownLinkOrImage.FindAllStringSubmatchIndex(part, -1) {
+			out.WriteString(renderMarkdownEmphasis(part[last:match[0]]))
+			last = match[1]
+
+			if match[2] >= 0 {
+				alt, target := part[match[2]:match[3]], part[match[4]:match[5]]
+				if source, ok := markdownImageSource(target, baseDir); ok {
+					out.WriteString(`<img src="` + source + `" alt="` + alt + `"/>`)
+				} else {
+					out.WriteString(alt)
+				}
+				continue
+			}
+			label, target := renderMarkdownEmphasis(part[match[6]:match[7]]), part[match[8]:match[9]]
+			if markdownTargetAllowed(target) {
+				out.WriteString(`<a href="` + target + `">` + label + `</a>`)
+			} else {
+				out.WriteString(label)
+			}
+		}
+		out.WriteString(renderMarkdownEmphasis(part[last:]))
+	}
+	return out.String()
+}
+
+func renderMarkdownEmphasis(text string) string {
+	text = markdownStrong.ReplaceAllString(text, "<strong>$1$2</strong>")
+	return markdownEmphasis.ReplaceAllString(text, "<em>$1$2</em>")
+}
+
+// markdownTargetAllowed reports whether an escaped link target is relative or uses one of
+// markdownSchemes.
+func markdownTargetAllowed(target string) bool {
+	parsed, err := url.Parse(html.UnescapeString(target))
+	return err == nil && (parsed.Scheme == "" || slices.Contains(markdownSchemes, parsed.Scheme))
+}
+
+// markdownImageSource returns the src of an image, relative paths are resolved against
+// baseDir. It reports false for schemes that are not allowed.
+func markdownImageSource(source string, baseDir string) (string, bool) {
+	if !markdownTargetAllowed(source) {
+		return "", false
+	}
+	if strings.Contains(source, "://") {
+		return source, true
+	}
+	return "file://" + html.EscapeString(filepath.Join(baseDir, html.UnescapeString(source))), true
+}
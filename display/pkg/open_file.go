@@ -3,51 +3,210 @@ package pkg
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"plg-mudics/shared"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
 
+	"github.com/a-h/templ"
 	"github.com/gabriel-vasile/mimetype"
 
 	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
 )
 
+var ErrFileTypeNotSupported = errors.New("file type not supported")
+
 var fileHandler fileHandlerType = fileHandlerType{}
 
 type fileHandlerType struct {
 	runningProgram *exec.Cmd
+	// mutex guards tempPaths, which layouts and the start screen add to as well
+	mutex sync.Mutex
+	// tempPaths are removed when the next file is opened
+	tempPaths []string
+}
+
+// Types that the browser shows directly.
+var (
+	browserVideoTypes = []string{"video/mp4", "video/webm", "video/ogg"}
+	browserImageTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "image/avif", "image/svg+xml", "image/bmp"}
+	browserAudioTypes = []string{"audio/mpeg", "audio/ogg", "audio/wav", "audio/flac", "audio/aac", "audio/mp4", "audio/x-m4a", "audio/webm"}
+)
+
+// Office text documents, converted to PDF when opened.
+var documentTypes = []string{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.oasis.opendocument.text",
+	"application/msword",
+	"text/rtf",
 }
 
-func OpenFile(path string) error {
+// maxTextFileSize keeps huge log files from freezing the browser.
+const maxTextFileSize = 1 << 20
+
+// OpenFile shows a file full screen. The display options apply to images, videos and the
+// visuals of audio files, the transition to everything shown in the browser. Unset fields
+// fall back to the display defaults.
+func OpenFile(path string, options api.DisplayOptions) error {
+	options, err := ResolveDisplayOptions(options)
+	if err != nil {
+		return err
+	}
+
 	ResetView()
+	applyTransition(options)
 
 	mType, err := mimetype.DetectFile(path)
 	if err != nil {
 		slog.Error("Failed to detect mime type", "file", path, "error", err)
 	}
 
-	switch mType.String() {
-	case "video/mp4":
-		var templateBuffer bytes.Buffer
-		videoTemplate(path).Render(context.Background(), &templateBuffer)
-		browser.Browser.OpenHTML(templateBuffer.String())
-	case "image/jpeg", "image/png", "image/gif":
-		var templateBuffer bytes.Buffer
-		imageTemplate(path).Render(context.Background(), &templateBuffer)
-		browser.Browser.OpenHTML(templateBuffer.String())
-	case "application/pdf":
-		browser.Browser.OpenPDF(path)
-	case "application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/vnd.oasis.opendocument.presentation":
+	switch {
+	case isAnyType(mType, browserVideoTypes):
+		err = openTemplate(videoTemplate(path, mType.String(), options))
+	case isAnyType(mType, browserImageTypes):
+		err = openTemplate(imageTemplate(path, options))
+	case isAnyType(mType, browserAudioTypes):
+		err = fileHandler.openAudio(path, mType, options)
+	case mType.Is("application/pdf"):
+		err = browser.Browser.OpenPDF(path)
+	case mType.Is("application/vnd.openxmlformats-officedocument.presentationml.presentation"), mType.Is("application/vnd.oasis.opendocument.presentation"):
+		if rendered, ok := presentationRenderer.cached(path); ok {
+			return openRenderedPresentation(rendered)
+		}
+		// shown with soffice this time, the slides are ready next time
+		if Config.PrerenderPresentations {
+			PrerenderPresentation(path)
+		}
 		err = fileHandler.openFileWithApp(path)
+	case isAnyType(mType, documentTypes):
+		err = fileHandler.openDocument(path)
+	case mType.Is("text/plain"):
+		err = openText(path)
+	case mType.Is("application/zip"):
+		err = openBundle(path)
 	default:
-		return fmt.Errorf("unsupported file type: %s", mType.String())
+		return fmt.Errorf("%w: %s", ErrFileTypeNotSupported, mType.String())
 	}
 
-	return nil
+	return err
+}
+
+func isAnyType(mType *mimetype.MIME, types []string) bool {
+	return slices.ContainsFunc(types, mType.Is)
+}
+
+func openTemplate(component templ.Component) error {
+	var templateBuffer bytes.Buffer
+	if err := component.Render(context.Background(), &templateBuffer); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return browser.Browser.OpenHTML(templateBuffer.String())
+}
+
+// openText shows plain text, or Markdown rendered to HTML for .md files.
+func openText(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxTextFileSize))
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return openTemplate(markdownTemplate(renderMarkdown(string(content), filepath.Dir(path))))
+	default:
+		return openTemplate(textTemplate(string(content)))
+	}
+}
+
+// openAudio plays the file with the cover art or the configured background as visual.
+func (fh *fileHandlerType) openAudio(path string, mType *mimetype.MIME, options api.DisplayOptions) error {
+	visual := ""
+	if Config.AudioVisual != AudioVisualBackground {
+		coverPath, err := fh.extractCoverArt(path)
+		if err != nil {
+			slog.Debug("No cover art, using the background", "file", path, "error", err)
+		}
+		visual = coverPath
+	}
+	if visual == "" && Config.AudioBackground != "" {
+		backgroundPath, exists, err := ResolveStorageFilePath(Config.AudioBackground)
+		if err != nil || !exists {
+			slog.Warn("Audio background not found", "path", Config.AudioBackground, "error", err)
+		} else {
+			visual = backgroundPath
+		}
+	}
+
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return openTemplate(audioTemplate(path, mType.String(), visual, title, options))
+}
+
+func (fh *fileHandlerType) extractCoverArt(path string) (string, error) {
+	tempFile, err := os.CreateTemp("", "plg-mudics-cover-*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempFile.Close()
+	fh.addTempPath(tempFile.Name())
+
+	cmd := exec.Command("ffmpeg", "-y", "-i", path, "-map", "0:v:0", "-frames:v", "1", tempFile.Name())
+	if err := runPreviewTool(cmd); err != nil {
+		return "", err
+	}
+	return tempFile.Name(), nil
+}
+
+// openDocument converts an office document to PDF and shows it.
+func (fh *fileHandlerType) openDocument(path string) error {
+	tempDir, err := os.MkdirTemp("", "plg-mudics-document-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	fh.addTempPath(tempDir)
+
+	pdfPath, err := convertDocumentToPDF(path, tempDir)
+	if err != nil {
+		if errors.Is(err, ErrFilePreviewToolsMissing) {
+			return fmt.Errorf("%w: soffice is missing", ErrFileTypeNotSupported)
+		}
+		return err
+	}
+
+	return browser.Browser.OpenPDF(pdfPath)
+}
+
+// addTempPath marks a file or directory to be removed when the next file is opened.
+func (fh *fileHandlerType) addTempPath(path string) {
+	fh.mutex.Lock()
+	defer fh.mutex.Unlock()
+	fh.tempPaths = append(fh.tempPaths, path)
+}
+
+// removeTempFiles deletes the files created for the last opened file.
+func (fh *fileHandlerType) removeTempFiles() {
+	fh.mutex.Lock()
+	defer fh.mutex.Unlock()
+
+	for _, path := range fh.tempPaths {
+		os.RemoveAll(path)
+	}
+	fh.tempPaths = nil
 }
 
 func (fh *fileHandlerType) openFileWithApp(path string) error {
@@ -65,7 +224,7 @@ func (fh *fileHandlerType) openFileWithApp(path string) error {
 			return err
 		}
 	default:
-		return fmt.Errorf("unsupported file type: %s", mType.String())
+		return fmt.Errorf("%w: %s", ErrFileTypeNotSupported, mType.String())
 	}
 
 	fh.runningProgram.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
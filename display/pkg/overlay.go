@@ -0,0 +1,334 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrOverlayInvalid = errors.New("invalid overlay")
+var ErrOverlayLogoNotFound = errors.New("overlay logo not found")
+
+var overlays = overlaysType{}
+
+// overlaysType holds the overlays, which are kept until they are changed or the display
+// restarts.
+type overlaysType struct {
+	mutex   sync.Mutex
+	current api.Overlays
+}
+
+// overlayScriptConfig is passed to overlayScript, with the defaults filled in.
+type overlayScriptConfig struct {
+	Clock      *api.ClockOverlay       `json:"clock"`
+	Ticker     *api.TickerOverlay      `json:"ticker"`
+	Logo       *overlayScriptLogo      `json:"logo"`
+	LowerThird *api.LowerThirdOverlay  `json:"lowerThird"`
+	Countdown  *overlayScriptCountdown `json:"countdown"`
+}
+
+type overlayScriptLogo struct {
+	// Source is a data URL, file URLs can not be loaded by websites.
+	Source string            `json:"source"`
+	Corner api.OverlayCorner `json:"corner"`
+	Height int               `json:"height"`
+}
+
+type overlayScriptCountdown struct {
+	// Until is in milliseconds since the epoch.
+	Until        int64             `json:"until"`
+	Label        string            `json:"label"`
+	FinishedText string            `json:"finishedText"`
+	Corner       api.OverlayCorner `json:"corner"`
+}
+
+// GetOverlays returns the overlays that are shown.
+func GetOverlays() api.Overlays {
+	overlays.mutex.Lock()
+	defer overlays.mutex.Unlock()
+	return overlays.current
+}
+
+// SetOverlays validates the overlays and shows them on top of the current and all following
+// content.
+func SetOverlays(newOverlays api.Overlays) error {
+	config, err := resolveOverlays(newOverlays)
+	if err != nil {
+		return err
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode overlays: %w", err)
+	}
+
+	overlays.mutex.Lock()
+	defer overlays.mutex.Unlock()
+
+	if err := browser.Browser.SetPageScript(strings.Replace(overlayScript, "OVERLAY_CONFIG", string(configJSON), 1)); err != nil {
+		return fmt.Errorf("failed to show overlays: %w", err)
+	}
+	overlays.current = newOverlays
+	return nil
+}
+
+func resolveOverlays(o api.Overlays) (overlayScriptConfig, error) {
+	config := overlayScriptConfig{LowerThird: o.LowerThird}
+
+	if o.Clock != nil {
+		clock := *o.Clock
+		if clock.Corner == "" {
+			clock.Corner = api.OverlayTopRight
+		}
+		if err := validateOverlayCorner("clock", clock.Corner); err != nil {
+			return overlayScriptConfig{}, err
+		}
+		config.Clock = &clock
+	}
+
+	if o.Ticker != nil {
+		ticker := *o.Ticker
+		if ticker.Edge == "" {
+			ticker.Edge = api.OverlayBottom
+		}
+		if ticker.Speed == 0 {
+			ticker.Speed = api.OverlayDefaultTickerSpeed
+		}
+		switch {
+		case strings.TrimSpace(ticker.Text) == "":
+			return overlayScriptConfig{}, fmt.Errorf("%w: ticker text is empty", ErrOverlayInvalid)
+		case !slices.Contains(ticker.Edge.EnumValues(), string(ticker.Edge)):
+			return overlayScriptConfig{}, fmt.Errorf("%w: ticker edge has to be one of %s", ErrOverlayInvalid, strings.Join(ticker.Edge.EnumValues(), ", "))
+		case ticker.Speed < 1 || ticker.Speed > api.OverlayMaxTickerSpeed:
+			return overlayScriptConfig{}, fmt.Errorf("%w: ticker speed has to be between 1 and %d", ErrOverlayInvalid, api.OverlayMaxTickerSpeed)
+		}
+		config.Ticker = &ticker
+	}
+
+	if o.Logo != nil {
+		logo, err := resolveOverlayLogo(*o.Logo)
+		if err != nil {
+			return overlayScriptConfig{}, err
+		}
+		config.Logo = &logo
+	}
+
+	if o.LowerThird != nil && strings.TrimSpace(o.LowerThird.Title) == "" {
+		return overlayScriptConfig{}, fmt.Errorf("%w: lower third title is empty", ErrOverlayInvalid)
+	}
+
+	if o.Countdown != nil {
+		until, err := time.Parse(time.RFC3339, o.Countdown.Until)
+		if err != nil {
+			return overlayScriptConfig{}, fmt.Errorf("%w: countdown until has to be an RFC 3339 time", ErrOverlayInvalid)
+		}
+		countdown := overlayScriptCountdown{
+			Until:        until.UnixMilli(),
+			Label:        o.Countdown.Label,
+			FinishedText: o.Countdown.FinishedText,
+			Corner:       o.Countdown.Corner,
+		}
+		if countdown.Corner == "" {
+			countdown.Corner = api.OverlayBottomRight
+		}
+		if err := validateOverlayCorner("countdown", countdown.Corner); err != nil {
+			return overlayScriptConfig{}, err
+		}
+		config.Countdown = &countdown
+	}
+
+	return config, nil
+}
+
+func resolveOverlayLogo(logo api.LogoOverlay) (overlayScriptLogo, error) {
+	resolved := overlayScriptLogo{Corner: logo.Corner, Height: logo.Height}
+	if resolved.Corner == "" {
+		resolved.Corner = api.OverlayTopLeft
+	}
+	if resolved.Height == 0 {
+		resolved.Height = api.OverlayDefaultLogoHeight
+	}
+	if err := validateOverlayCorner("logo", resolved.Corner); err != nil {
+		return overlayScriptLogo{}, err
+	}
+	if resolved.Height < 1 || resolved.Height > 100 {
+		return overlayScriptLogo{}, fmt.Errorf("%w: logo height has to be between 1 and 100", ErrOverlayInvalid)
+	}
+
+	path, exists, err := ResolveStorageFilePath(logo.Path)
+	if err != nil {
+		return overlayScriptLogo{}, err
+	}
+	if !exists {
+		return overlayScriptLogo{}, fmt.Errorf("%w: %s", ErrOverlayLogoNotFound, logo.Path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return overlayScriptLogo{}, fmt.Errorf("failed to stat logo: %w", err)
+	}
+	if info.Size() > api.OverlayMaxLogoSize {
+		return overlayScriptLogo{}, fmt.Errorf("%w: logo is larger than %d bytes", ErrOverlayInvalid, api.OverlayMaxLogoSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return overlayScriptLogo{}, fmt.Errorf("failed to read logo: %w", err)
+	}
+	mType := mimetype.Detect(data)
+	if !isAnyType(mType, browserImageTypes) {
+		return overlayScriptLogo{}, fmt.Errorf("%w: logo is no image but %s", ErrOverlayInvalid, mType.String())
+	}
+
+	resolved.Source = "data:" + mType.String() + ";base64," + base64.StdEncoding.EncodeToString(data)
+	return resolved, nil
+}
+
+func validateOverlayCorner(name string, corner api.OverlayCorner) error {
+	if !slices.Contains(corner.EnumValues(), string(corner)) {
+		return fmt.Errorf("%w: %s corner has to be one of %s", ErrOverlayInvalid, name, strings.Join(corner.EnumValues(), ", "))
+	}
+	return nil
+}
+
+// overlayScript draws the overlays into a shadow root on top of the page, so that the styles
+// of websites do not change them. It only runs in the top frame, not in the frames of the
+// player. OVERLAY_CONFIG is replaced with the overlayScriptConfig.
+const overlayScript = `(() => {
+	const config = OVERLAY_CONFIG;
+	if (window.top !== window) {
+		return;
+	}
+
+	const render = () => {
+		if (window.plgMudicsOverlay) {
+			window.plgMudicsOverlay.remove();
+		}
+		const timers = [];
+		const host = document.createElement('plg-mudics-overlay');
+		host.style.cssText = 'position: fixed; inset: 0; z-index: 2147483647; pointer-events: none;';
+		const root = host.attachShadow({ mode: 'closed' });
+		window.plgMudicsOverlay = {
+			remove() {
+				timers.forEach(clearInterval);
+				host.remove();
+			},
+		};
+
+		const style = document.createElement('style');
+		style.textContent = ` + "`" + `
+			* { box-sizing: border-box; }
+			:host { --edge: 3vh; --ticker-height: 7vh; font-family: ui-sans-serif, system-ui, sans-serif; color: white; }
+			.box { position: absolute; padding: 1vh 1.5vh; border-radius: 1vh; background: rgb(0 0 0 / 0.6); font-size: 4vh; line-height: 1.2; font-variant-numeric: tabular-nums; }
+			.top-left { top: var(--edge); left: var(--edge); }
+			.top-right { top: var(--edge); right: var(--edge); text-align: right; }
+			.bottom-left { bottom: var(--edge); left: var(--edge); }
+			.bottom-right { bottom: var(--edge); right: var(--edge); text-align: right; }
+			.ticker-top .top-left, .ticker-top .top-right { top: calc(var(--ticker-height) + var(--edge)); }
+			.ticker-bottom .bottom-left, .ticker-bottom .bottom-right, .ticker-bottom .lower-third { bottom: calc(var(--ticker-height) + var(--edge)); }
+			.label { font-size: 2.4vh; opacity: 0.8; }
+			img.box { padding: 0; background: none; border-radius: 0; }
+			.ticker { position: absolute; left: 0; width: 100%; height: var(--ticker-height); overflow: hidden; background: rgb(0 0 0 / 0.75); font-size: 4vh; line-height: var(--ticker-height); white-space: nowrap; }
+			.ticker.top { top: 0; }
+			.ticker.bottom { bottom: 0; }
+			.ticker span { display: inline-block; padding-right: 10vw; }
+			.lower-third { position: absolute; left: 0; bottom: var(--edge); max-width: 70vw; padding: 1.5vh 3vh 1.5vh 5vh; background: rgb(0 0 0 / 0.75); border-left: 1vh solid white; }
+			.lower-third .title { font-size: 5vh; font-weight: bold; }
+			.lower-third .subtitle { font-size: 3vh; opacity: 0.8; }
+		` + "`" + `;
+		const container = document.createElement('div');
+		root.append(style, container);
+		// attached first, the ticker needs the width of its text
+		document.documentElement.append(host);
+
+		const element = (tag, className, text) => {
+			const child = document.createElement(tag);
+			child.className = className;
+			if (text !== undefined) {
+				child.textContent = text;
+			}
+			return child;
+		};
+		const every = (interval, update) => {
+			update();
+			timers.push(setInterval(update, interval));
+		};
+
+		if (config.ticker) {
+			container.classList.add('ticker-' + config.ticker.edge);
+			const ticker = element('div', 'ticker ' + config.ticker.edge);
+			const text = element('span', '', config.ticker.text);
+			ticker.append(text);
+			container.append(ticker);
+			const width = text.offsetWidth;
+			text.animate(
+				[{ transform: 'translateX(' + window.innerWidth + 'px)' }, { transform: 'translateX(' + -width + 'px)' }],
+				{ duration: ((window.innerWidth + width) / config.ticker.speed) * 1000, iterations: Infinity },
+			);
+		}
+
+		if (config.logo) {
+			const logo = element('img', 'box ' + config.logo.corner);
+			logo.src = config.logo.source;
+			logo.style.height = config.logo.height + 'vh';
+			container.append(logo);
+		}
+
+		if (config.clock) {
+			const clock = element('div', 'box ' + config.clock.corner);
+			container.append(clock);
+			const format = { hour: '2-digit', minute: '2-digit' };
+			if (config.clock.seconds) {
+				format.second = '2-digit';
+			}
+			every(1000, () => (clock.textContent = new Date().toLocaleTimeString([], format)));
+		}
+
+		if (config.lowerThird) {
+			const lowerThird = element('div', 'lower-third');
+			lowerThird.append(element('div', 'title', config.lowerThird.title));
+			if (config.lowerThird.subtitle) {
+				lowerThird.append(element('div', 'subtitle', config.lowerThird.subtitle));
+			}
+			container.append(lowerThird);
+		}
+
+		if (config.countdown) {
+			const countdown = element('div', 'box ' + config.countdown.corner);
+			if (config.countdown.label) {
+				countdown.append(element('div', 'label', config.countdown.label));
+			}
+			const time = element('div', '');
+			countdown.append(time);
+			container.append(countdown);
+			const pad = (value) => String(value).padStart(2, '0');
+			every(250, () => {
+				const left = Math.ceil((config.countdown.until - Date.now()) / 1000);
+				if (left <= 0) {
+					time.textContent = config.countdown.finishedText || '0:00';
+					return;
+				}
+				const hours = Math.floor(left / 3600);
+				const minutes = Math.floor((left % 3600) / 60);
+				const seconds = left % 60;
+				time.textContent = hours > 0 ? hours + ':' + pad(minutes) + ':' + pad(seconds) : minutes + ':' + pad(seconds);
+			});
+		}
+	};
+
+	if (document.readyState === 'loading') {
+		document.addEventListener('DOMContentLoaded', render);
+	} else {
+		render();
+	}
+})();
+`
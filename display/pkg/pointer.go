@@ -0,0 +1,244 @@
+package pkg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"plg-mudics/shared/api"
+)
+
+var ErrPointerInputInvalid = errors.New("invalid pointer input")
+
+// Constants of linux/uinput.h and linux/input-event-codes.h.
+const (
+	uiDevCreate  = 0x5501
+	uiSetEvBit   = 0x40045564
+	uiSetKeyBit  = 0x40045565
+	uiSetRelBit  = 0x40045566
+	uiSetAbsBit  = 0x40045567
+	evSyn        = 0x00
+	evKey        = 0x01
+	evRel        = 0x02
+	evAbs        = 0x03
+	synReport    = 0x00
+	btnLeft      = 0x110
+	btnRight     = 0x111
+	btnMiddle    = 0x112
+	relHWheel    = 0x06
+	relWheel     = 0x08
+	absX         = 0x00
+	absY         = 0x01
+	absCount     = 0x40
+	uinputName   = 80
+	pointerRange = 65535
+)
+
+var pointerButtons = map[api.MouseButton]uint16{
+	api.MouseButtonLeft:   btnLeft,
+	api.MouseButtonMiddle: btnMiddle,
+	api.MouseButtonRight:  btnRight,
+}
+
+var pointer = pointerType{x: 0.5, y: 0.5}
+
+// pointerType is a virtual absolute mouse, like the tablet of virtual machines. It
+// remembers the position to support relative moves, starting in the center of the screen.
+type pointerType struct {
+	mutex  sync.Mutex
+	device *os.File
+	x, y   float64
+}
+
+// uinputUserDev is struct uinput_user_dev.
+type uinputUserDev struct {
+	Name         [uinputName]byte
+	BusType      uint16
+	Vendor       uint16
+	Product      uint16
+	Version      uint16
+	FFEffectsMax uint32
+	AbsMax       [absCount]int32
+	AbsMin       [absCount]int32
+	AbsFuzz      [absCount]int32
+	AbsFlat      [absCount]int32
+}
+
+// inputEvent is struct input_event, the kernel fills in the time.
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// PointerInput moves the mouse, presses its buttons and turns its wheel through uinput, so
+// that it works in every program of the session, like KeyboardInput. All inputs are checked
+// before the first one is sent.
+func PointerInput(inputs []api.PointerInput) error {
+	for i := range inputs {
+		if inputs[i].Button == "" {
+			inputs[i].Button = api.MouseButtonLeft
+		}
+		if err := validatePointerInput(inputs[i]); err != nil {
+			return err
+		}
+	}
+
+	pointer.mutex.Lock()
+	defer pointer.mutex.Unlock()
+
+	if err := pointer.open(); err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		if err := pointer.send(input); err != nil {
+			return fmt.Errorf("failed to run pointer event: %w", err)
+		}
+		time.Sleep(time.Microsecond * 10)
+	}
+	return nil
+}
+
+func validatePointerInput(input api.PointerInput) error {
+	limit := 0.0
+	if input.Relative {
+		limit = -1
+	}
+	switch {
+	case input.X < limit || input.X > 1 || input.Y < limit || input.Y > 1:
+		if input.Relative {
+			return fmt.Errorf("%w: relative x and y have to be between -1 and 1", ErrPointerInputInvalid)
+		}
+		return fmt.Errorf("%w: x and y have to be between 0 and 1", ErrPointerInputInvalid)
+	case pointerButtons[input.Button] == 0:
+		return fmt.Errorf("%w: button has to be one of %s", ErrPointerInputInvalid, strings.Join(input.Button.EnumValues(), ", "))
+	case abs(input.DeltaX) > api.PointerMaxScroll || abs(input.DeltaY) > api.PointerMaxScroll:
+		return fmt.Errorf("%w: scroll has to be at most %d notches", ErrPointerInputInvalid, api.PointerMaxScroll)
+	}
+
+	switch input.Action {
+	case api.PointerMove, api.PointerPress, api.PointerRelease, api.PointerClick, api.PointerDoubleClick, api.PointerScroll:
+		return nil
+	default:
+		return fmt.Errorf("%w: action has to be one of %s", ErrPointerInputInvalid, strings.Join(input.Action.EnumValues(), ", "))
+	}
+}
+
+func (p *pointerType) send(input api.PointerInput) error {
+	button := pointerButtons[input.Button]
+	switch input.Action {
+	case api.PointerMove:
+		if input.Relative {
+			p.x += input.X
+			p.y += input.Y
+		} else {
+			p.x, p.y = input.X, input.Y
+		}
+		p.x = min(max(p.x, 0), 1)
+		p.y = min(max(p.y, 0), 1)
+		return p.write(
+			inputEvent{Type: evAbs, Code: absX, Value: int32(math.Round(p.x * pointerRange))},
+			inputEvent{Type: evAbs, Code: absY, Value: int32(math.Round(p.y * pointerRange))},
+		)
+	case api.PointerPress:
+		return p.write(inputEvent{Type: evKey, Code: button, Value: 1})
+	case api.PointerRelease:
+		return p.write(inputEvent{Type: evKey, Code: button, Value: 0})
+	case api.PointerClick, api.PointerDoubleClick:
+		clicks := 1
+		if input.Action == api.PointerDoubleClick {
+			clicks = 2
+		}
+		for range clicks {
+			if err := p.write(inputEvent{Type: evKey, Code: button, Value: 1}); err != nil {
+				return err
+			}
+			if err := p.write(inputEvent{Type: evKey, Code: button, Value: 0}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case api.PointerScroll:
+		// the wheel counts upwards, the API downwards like browsers
+		return p.write(
+			inputEvent{Type: evRel, Code: relWheel, Value: int32(-input.DeltaY)},
+			inputEvent{Type: evRel, Code: relHWheel, Value: int32(input.DeltaX)},
+		)
+	}
+	return nil
+}
+
+// write sends events followed by a report, which makes them take effect together.
+func (p *pointerType) write(events ...inputEvent) error {
+	events = append(events, inputEvent{Type: evSyn, Code: synReport})
+	return binary.Write(p.device, binary.NativeEndian, events)
+}
+
+// open creates the virtual mouse on first use. Like for the keyboard, /dev/uinput has to be
+// writable for the user of the display.
+func (p *pointerType) open() error {
+	if p.device != nil {
+		return nil
+	}
+
+	path := "/dev/uinput"
+	if _, err := os.Stat(path); err != nil {
+		path = "/dev/input/uinput"
+	}
+	device, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open uinput: %w", err)
+	}
+
+	bits := []struct {
+		request uintptr
+		values  []uintptr
+	}{
+		{uiSetEvBit, []uintptr{evSyn, evKey, evRel, evAbs}},
+		{uiSetKeyBit, []uintptr{btnLeft, btnRight, btnMiddle}},
+		{uiSetRelBit, []uintptr{relWheel, relHWheel}},
+		{uiSetAbsBit, []uintptr{absX, absY}},
+	}
+	for _, bit := range bits {
+		for _, value := range bit.values {
+			if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, device.Fd(), bit.request, value); errno != 0 {
+				device.Close()
+				return fmt.Errorf("failed to set up virtual mouse: %w", errno)
+			}
+		}
+	}
+
+	setup := uinputUserDev{BusType: 0x03, Vendor: 0x1, Product: 0x2, Version: 0x1}
+	copy(setup.Name[:], "PLG MuDiCS pointer")
+	setup.AbsMax[absX] = pointerRange
+	setup.AbsMax[absY] = pointerRange
+	if err := binary.Write(device, binary.NativeEndian, &setup); err != nil {
+		device.Close()
+		return fmt.Errorf("failed to set up virtual mouse: %w", err)
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, device.Fd(), uiDevCreate, 0); errno != 0 {
+		device.Close()
+		return fmt.Errorf("failed to create virtual mouse: %w", errno)
+	}
+
+	// events are lost until the display server picked up the new device
+	time.Sleep(time.Second)
+	slog.Info("Virtual mouse created", "path", path)
+	p.device = device
+	return nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,199 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/display/browser"
+)
+
+const presentationCacheName = "presentation-cache"
+
+// fallbackSlideWidth and fallbackSlideHeight are used when the screen size can not be read.
+const fallbackSlideWidth = 1920
+const fallbackSlideHeight = 1080
+
+var presentationRenderer = presentationRendererType{running: map[string]bool{}}
+
+// presentationRendererType converts presentations once into one image per slide, stored in
+// the storage directory and keyed like the previews. The slides are shown in the browser,
+// which switches between them instantly.
+type presentationRendererType struct {
+	mutex   sync.Mutex
+	running map[string]bool
+	// only one soffice conversion at a time, they are heavy and share the profile directory
+	slot sync.Mutex
+}
+
+// renderedPresentation is a finished conversion. Slides is empty if ghostscript is missing,
+// the PDF is shown instead.
+type renderedPresentation struct {
+	PDF    string
+	Slides []string
+}
+
+// PrerenderPresentation converts a presentation in the background, if it is not converted yet.
+// Other files are ignored.
+func PrerenderPresentation(path string) {
+	mType, err := mimetype.DetectFile(path)
+	if err != nil || !isPresentation(mType) {
+		return
+	}
+
+	go func() {
+		if _, err := presentationRenderer.render(path); err != nil {
+			slog.Error("Failed to pre-render presentation", "file", path, "error", err)
+		}
+	}()
+}
+
+// cached returns the finished conversion of a presentation, or false if there is none.
+func (pr *presentationRendererType) cached(path string) (renderedPresentation, bool) {
+	cachePath, err := getCachePath(presentationCacheName)
+	if err != nil {
+		return renderedPresentation{}, false
+	}
+	_, key, err := cacheSource(path)
+	if err != nil {
+		return renderedPresentation{}, false
+	}
+
+	rendered, err := readRenderedPresentation(filepath.Join(cachePath, key))
+	return rendered, err == nil
+}
+
+func readRenderedPresentation(dir string) (renderedPresentation, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return renderedPresentation{}, err
+	}
+
+	rendered := renderedPresentation{PDF: filepath.Join(dir, "slides.pdf")}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "slide-") {
+			rendered.Slides = append(rendered.Slides, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// the names are zero padded, so this is the slide order
+	slices.Sort(rendered.Slides)
+	return rendered, nil
+}
+
+func (pr *presentationRendererType) render(path string) (renderedPresentation, error) {
+	if rendered, ok := pr.cached(path); ok {
+		return rendered, nil
+	}
+
+	cachePath, err := getCachePath(presentationCacheName)
+	if err != nil {
+		return renderedPresentation{}, err
+	}
+	source, key, err := cacheSource(path)
+	if err != nil {
+		return renderedPresentation{}, err
+	}
+
+	pr.mutex.Lock()
+	if pr.running[key] {
+		pr.mutex.Unlock()
+		return renderedPresentation{}, errors.New("presentation is already being rendered")
+	}
+	pr.running[key] = true
+	pr.mutex.Unlock()
+	defer func() {
+		pr.mutex.Lock()
+		delete(pr.running, key)
+		pr.mutex.Unlock()
+	}()
+
+	pr.slot.Lock()
+	defer pr.slot.Unlock()
+
+	// everything is written into a temporary directory that is renamed when complete
+	tempDir, err := os.MkdirTemp(cachePath, ".render-*")
+	if err != nil {
+		return renderedPresentation{}, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	pdfPath, err := convertDocumentToPDF(path, tempDir)
+	if err != nil {
+		return renderedPresentation{}, err
+	}
+	if err := os.Rename(pdfPath, filepath.Join(tempDir, "slides.pdf")); err != nil {
+		return renderedPresentation{}, fmt.Errorf("failed to move pdf: %w", err)
+	}
+
+	if err := renderPDFSlides(filepath.Join(tempDir, "slides.pdf"), tempDir); err != nil {
+		if !errors.Is(err, ErrFilePreviewToolsMissing) {
+			return renderedPresentation{}, err
+		}
+		slog.Warn("Ghostscript is missing, presentation will be shown as PDF", "file", path)
+	}
+
+	if err := writeCacheSource(tempDir, source); err != nil {
+		return renderedPresentation{}, err
+	}
+
+	targetDir := filepath.Join(cachePath, key)
+	if err := os.Rename(tempDir, targetDir); err != nil {
+		return renderedPresentation{}, fmt.Errorf("failed to move rendered presentation: %w", err)
+	}
+
+	slog.Info("Presentation pre-rendered", "file", path)
+	return readRenderedPresentation(targetDir)
+}
+
+// convertDocumentToPDF converts a presentation or text document with soffice and returns
+// the path of the PDF in outputDir.
+func convertDocumentToPDF(path string, outputDir string) (string, error) {
+	profileDir, err := os.MkdirTemp("", "plg-mudics-program-profile-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary profile directory: %w", err)
+	}
+	defer os.RemoveAll(profileDir)
+
+	// headless conversions do not create lock files, unlike --show
+	cmd := exec.Command("soffice", "--headless", "--convert-to", "pdf", "--outdir", outputDir, fmt.Sprintf("-env:UserInstallation=file://%s", profileDir), path)
+	if err := runPreviewTool(cmd); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(outputDir, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+".pdf"), nil
+}
+
+// renderPDFSlides writes one PNG per page, fitted to the screen.
+func renderPDFSlides(pdfPath string, outputDir string) error {
+	width, height, err := browser.Browser.ScreenResolution()
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = fallbackSlideWidth, fallbackSlideHeight
+	}
+
+	cmd := exec.Command("gs", "-q", "-dSAFER", "-dBATCH", "-dNOPAUSE", "-sDEVICE=png16m",
+		"-dTextAlphaBits=4", "-dGraphicsAlphaBits=4", "-dPDFFitPage", fmt.Sprintf("-g%dx%d", width, height),
+		"-o", filepath.Join(outputDir, "slide-%04d.png"), pdfPath)
+	return runPreviewTool(cmd)
+}
+
+func openRenderedPresentation(rendered renderedPresentation) error {
+	if len(rendered.Slides) == 0 {
+		return browser.Browser.OpenPDF(rendered.PDF)
+	}
+
+	var templateBuffer bytes.Buffer
+	if err := presentationTemplate(rendered.Slides).Render(context.Background(), &templateBuffer); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return browser.Browser.OpenHTML(templateBuffer.String())
+}
@@ -0,0 +1,109 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/chromedp/cdproto/input"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrRemoteControlInvalid = errors.New("invalid remote control request")
+
+// Scroll scrolls the shown content by an offset or to a position, see api.ScrollRequest.
+func Scroll(request api.ScrollRequest) error {
+	switch {
+	case (request.By == nil) == (request.To == nil):
+		return fmt.Errorf("%w: exactly one of by and to is required", ErrRemoteControlInvalid)
+	case request.By != nil:
+		if math.Abs(request.By.X) > api.ScrollMaxOffset || math.Abs(request.By.Y) > api.ScrollMaxOffset {
+			return fmt.Errorf("%w: offset has to be at most %d pixels", ErrRemoteControlInvalid, api.ScrollMaxOffset)
+		}
+		return browser.Browser.Scroll(request.By.X, request.By.Y)
+	default:
+		if !normalized(request.To.X) || !normalized(request.To.Y) {
+			return fmt.Errorf("%w: position has to be between 0 and 1", ErrRemoteControlInvalid)
+		}
+		return browser.Browser.ScrollTo(request.To.X, request.To.Y)
+	}
+}
+
+// Zoom zooms the shown content and returns the new factor.
+func Zoom(request api.ZoomRequest) (float64, error) {
+	factor := 1.0
+	switch request.Action {
+	case api.ZoomIn, api.ZoomOut:
+		current, err := browser.Browser.ZoomFactor()
+		if err != nil {
+			return 0, err
+		}
+		if request.Action == api.ZoomIn {
+			factor = current * api.ZoomStep
+		} else {
+			factor = current / api.ZoomStep
+		}
+		factor = min(max(factor, api.ZoomMinFactor), api.ZoomMaxFactor)
+	case api.ZoomReset:
+	case api.ZoomSet:
+		if request.Factor < api.ZoomMinFactor || request.Factor > api.ZoomMaxFactor {
+			return 0, fmt.Errorf("%w: factor has to be between %d and %d", ErrRemoteControlInvalid, api.ZoomMinFactor, api.ZoomMaxFactor)
+		}
+		factor = request.Factor
+	default:
+		return 0, fmt.Errorf("%w: action has to be one of %s", ErrRemoteControlInvalid, strings.Join(request.Action.EnumValues(), ", "))
+	}
+
+	if err := browser.Browser.Zoom(factor); err != nil {
+		return 0, err
+	}
+	return factor, nil
+}
+
+var mouseButtons = map[api.MouseButton]input.MouseButton{
+	api.MouseButtonLeft:   input.Left,
+	api.MouseButtonMiddle: input.Middle,
+	api.MouseButtonRight:  input.Right,
+}
+
+// Click clicks at a normalized position of the screen.
+func Click(request api.ClickRequest) error {
+	if request.Button == "" {
+		request.Button = api.MouseButtonLeft
+	}
+	if request.Count == 0 {
+		request.Count = 1
+	}
+
+	button, ok := mouseButtons[request.Button]
+	switch {
+	case !normalized(request.X) || !normalized(request.Y):
+		return fmt.Errorf("%w: position has to be between 0 and 1", ErrRemoteControlInvalid)
+	case !ok:
+		return fmt.Errorf("%w: button has to be one of %s", ErrRemoteControlInvalid, strings.Join(request.Button.EnumValues(), ", "))
+	case request.Count < 1 || request.Count > api.ClickMaxCount:
+		return fmt.Errorf("%w: count has to be between 1 and %d", ErrRemoteControlInvalid, api.ClickMaxCount)
+	}
+	return browser.Browser.Click(request.X, request.Y, button, request.Count)
+}
+
+// Navigate goes back or forward in the history of the open website, or reloads it.
+func Navigate(request api.NavigateRequest) error {
+	switch request.Action {
+	case api.NavigateBack:
+		return browser.Browser.NavigateBack()
+	case api.NavigateForward:
+		return browser.Browser.NavigateForward()
+	case api.NavigateReload:
+		return browser.Browser.Reload()
+	default:
+		return fmt.Errorf("%w: action has to be one of %s", ErrRemoteControlInvalid, strings.Join(request.Action.EnumValues(), ", "))
+	}
+}
+
+func normalized(value float64) bool {
+	return value >= 0 && value <= 1
+}
@@ -1,49 +1,25 @@
 package pkg
 
 import (
-	"bytes"
-	"context"
 	"fmt"
 	"image/color"
 	"log/slog"
 	"net"
 	"os"
-	"plg-mudics/shared"
-	"strings"
-
-	"plg-mudics/display/browser"
 
 	"github.com/skip2/go-qrcode"
 )
 
+// OpenStartScreen shows the idle screen after starting, or the info panel if that fails.
 func OpenStartScreen() {
-	var err error
-
-	raw := shared.RawSplashScreenTemplate
-	html := strings.ReplaceAll(raw, "%%APP-VERSION%%", shared.Version)
-
-	ip, err := getDeviceIp()
-	if err != nil {
-		slog.Error("Failed to get device IP", "error", err)
+	err := ShowIdleScreen()
+	if err == nil {
+		return
 	}
-	mac, err := getDeviceMac()
-	if err != nil {
-		slog.Error("Failed to get device MAC address", "error", err)
+	slog.Error("Failed to show idle screen, showing the info panel", "error", err)
+	if err := openInfoScreen(""); err != nil {
+		slog.Error("Failed to show info panel", "error", err)
 	}
-
-	port := 8080
-	showQrCode := !isPortFree(port)
-	qrCodePath := ""
-	if showQrCode {
-		qrCodePath, err = generateQRCode(fmt.Sprintf("http://%s:%d", ip, port))
-		if err != nil {
-			slog.Error("could not generate qr code", "error", err)
-		}
-	}
-
-	var templateBuffer bytes.Buffer
-	startScreenTemplate(html, ip, mac, qrCodePath).Render(context.Background(), &templateBuffer)
-	browser.Browser.OpenHTML(templateBuffer.String())
 }
 
 func getDeviceIp() (string, error) {
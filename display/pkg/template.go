@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"plg-mudics/shared/api"
+)
+
+var ErrTemplateInvalid = errors.New("invalid template parameters")
+var ErrTemplateNotFound = errors.New("template not found")
+
+// maxTemplateDuration limits countdowns, so that a typo does not run for years.
+const maxTemplateDuration = 7 * 24 * time.Hour
+
+// countdownConfig is what the countdown template needs, Until is in Unix milliseconds.
+type countdownConfig struct {
+	Until        int64  `json:"until"`
+	FinishedText string `json:"finishedText"`
+}
+
+// clockConfig is what the clock template needs.
+type clockConfig struct {
+	Style   api.ClockStyle `json:"style"`
+	Seconds bool           `json:"seconds"`
+}
+
+// ShowTemplate shows a built-in template. Everything that moves runs on the display, so that
+// countdowns and clocks stay accurate without the control server.
+func ShowTemplate(name api.TemplateName, parameters api.TemplateParameters) error {
+	component, err := templateComponent(name, parameters)
+	if err != nil {
+		return err
+	}
+
+	options, err := ResolveDisplayOptions(api.DisplayOptions{})
+	if err != nil {
+		slog.Warn("Failed to read display options, using the defaults", "error", err)
+	}
+	ResetView()
+	applyTransition(options)
+
+	return openTemplate(component)
+}
+
+func templateComponent(name api.TemplateName, parameters api.TemplateParameters) (templ.Component, error) {
+	switch name {
+	case api.TemplateTitle:
+		if strings.TrimSpace(parameters.Title) == "" {
+			return nil, fmt.Errorf("%w: title is required", ErrTemplateInvalid)
+		}
+		return titleSlideTemplate(parameters.Title, parameters.Subtitle), nil
+
+	case api.TemplateBullets:
+		if len(parameters.Items) == 0 || len(parameters.Items) > api.TemplateMaxBullets {
+			return nil, fmt.Errorf("%w: bullets have 1 to %d items", ErrTemplateInvalid, api.TemplateMaxBullets)
+		}
+		return bulletSlideTemplate(parameters.Title, parameters.Items), nil
+
+	case api.TemplateCountdown:
+		until, err := countdownEnd(parameters)
+		if err != nil {
+			return nil, err
+		}
+		return countdownTemplate(parameters.Label, countdownConfig{Until: until.UnixMilli(), FinishedText: parameters.FinishedText}), nil
+
+	case api.TemplateStopwatch:
+		return stopwatchTemplate(parameters.Label), nil
+
+	case api.TemplateClock:
+		style := parameters.Style
+		if style == "" {
+			style = api.ClockDigital
+		}
+		if !slices.Contains(style.EnumValues(), string(style)) {
+			return nil, fmt.Errorf("%w: style has to be one of %s", ErrTemplateInvalid, strings.Join(style.EnumValues(), ", "))
+		}
+		return clockTemplate(parameters.Label, clockConfig{Style: style, Seconds: parameters.Seconds}), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s, has to be one of %s", ErrTemplateNotFound, name, strings.Join(name.EnumValues(), ", "))
+	}
+}
+
+func countdownEnd(parameters api.TemplateParameters) (time.Time, error) {
+	switch {
+	case (parameters.Until == "") == (parameters.Duration == 0):
+		return time.Time{}, fmt.Errorf("%w: a countdown has either until or duration", ErrTemplateInvalid)
+	case parameters.Duration < 0 || time.Duration(parameters.Duration)*time.Second > maxTemplateDuration:
+		return time.Time{}, fmt.Errorf("%w: duration has to be between 1 and %d seconds", ErrTemplateInvalid, int(maxTemplateDuration.Seconds()))
+	case parameters.Duration > 0:
+		return time.Now().Add(time.Duration(parameters.Duration) * time.Second), nil
+	}
+
+	until, err := time.Parse(time.RFC3339, parameters.Until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: until has to be an RFC 3339 time", ErrTemplateInvalid)
+	}
+	if time.Until(until) > maxTemplateDuration {
+		return time.Time{}, fmt.Errorf("%w: until is more than %d days away", ErrTemplateInvalid, int(maxTemplateDuration.Hours()/24))
+	}
+	return until, nil
+}
+
+// tickEnd makes the hour ticks of the analog clock at 12, 3, 6 and 9 longer.
+func tickEnd(hour int) string {
+	if hour%3 == 0 {
+		return "-38"
+	}
+	return "-42"
+}
+
+func tickRotation(hour int) string {
+	return fmt.Sprintf("rotate(%d)", hour*30)
+}
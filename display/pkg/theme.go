@@ -0,0 +1,281 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/shared/api"
+)
+
+var ErrThemeInvalid = errors.New("invalid theme")
+var ErrThemeNotFound = errors.New("theme not found")
+
+var themes = themesType{}
+
+// themesType holds the stored themes. They are stored in the storage directory, so they
+// survive restarts.
+type themesType struct {
+	mutex  sync.Mutex
+	loaded bool
+	stored storedThemes
+}
+
+type storedThemes struct {
+	Active string               `json:"active"`
+	Themes map[string]api.Theme `json:"themes"`
+}
+
+// themeLook is the active theme as the templates need it.
+type themeLook struct {
+	Variables       string
+	BackgroundImage string
+	Logo            string
+	CSS             string
+}
+
+var themeName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// cssFontFamily allows font names and quotes, but nothing that could end the CSS declaration.
+var cssFontFamily = regexp.MustCompile(`^[a-zA-Z0-9 ,'"_-]+$`)
+
+var cssLength = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(px|pt|em|rem|vh|vw|vmin|vmax|%)$`)
+
+func getThemesPath() (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	// hidden, so that it does not show up in the file list
+	return filepath.Join(storagePath, ".themes.json"), nil
+}
+
+// GetThemes returns the stored themes sorted by name.
+func GetThemes() (api.ThemesResponse, error) {
+	themes.mutex.Lock()
+	defer themes.mutex.Unlock()
+
+	if err := themes.load(); err != nil {
+		return api.ThemesResponse{}, err
+	}
+	response := api.ThemesResponse{Active: themes.stored.Active, Themes: []api.Theme{}}
+	for _, theme := range themes.stored.Themes {
+		response.Themes = append(response.Themes, theme)
+	}
+	slices.SortFunc(response.Themes, func(a, b api.Theme) int { return strings.Compare(a.Name, b.Name) })
+	return response, nil
+}
+
+// SetTheme validates and stores a theme. A changed active theme applies to content opened
+// afterwards.
+func SetTheme(theme api.Theme) error {
+	if err := validateTheme(theme); err != nil {
+		return err
+	}
+
+	themes.mutex.Lock()
+	defer themes.mutex.Unlock()
+
+	if err := themes.load(); err != nil {
+		return err
+	}
+	stored := themes.stored
+	stored.Themes = make(map[string]api.Theme, len(themes.stored.Themes)+1)
+	for name, existing := range themes.stored.Themes {
+		stored.Themes[name] = existing
+	}
+	stored.Themes[theme.Name] = theme
+	return themes.write(stored)
+}
+
+// ActivateTheme makes a stored theme the active one, or the built-in look for an empty name.
+func ActivateTheme(name string) error {
+	themes.mutex.Lock()
+	defer themes.mutex.Unlock()
+
+	if err := themes.load(); err != nil {
+		return err
+	}
+	if _, exists := themes.stored.Themes[name]; name != "" && !exists {
+		return fmt.Errorf("%w: %s", ErrThemeNotFound, name)
+	}
+	stored := themes.stored
+	stored.Active = name
+	return themes.write(stored)
+}
+
+// DeleteTheme removes a stored theme. Removing the active theme goes back to the built-in look.
+func DeleteTheme(name string) error {
+	themes.mutex.Lock()
+	defer themes.mutex.Unlock()
+
+	if err := themes.load(); err != nil {
+		return err
+	}
+	if _, exists := themes.stored.Themes[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrThemeNotFound, name)
+	}
+	stored := storedThemes{Active: themes.stored.Active, Themes: map[string]api.Theme{}}
+	for existingName, existing := range themes.stored.Themes {
+		if existingName != name {
+			stored.Themes[existingName] = existing
+		}
+	}
+	if stored.Active == name {
+		stored.Active = ""
+	}
+	return themes.write(stored)
+}
+
+func (t *themesType) load() error {
+	if t.loaded {
+		return nil
+	}
+
+	path, err := getThemesPath()
+	if err != nil {
+		return err
+	}
+	t.stored = storedThemes{Themes: map[string]api.Theme{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read themes: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.stored); err != nil {
+		return fmt.Errorf("failed to parse themes: %w", err)
+	}
+	if t.stored.Themes == nil {
+		t.stored.Themes = map[string]api.Theme{}
+	}
+
+	t.loaded = true
+	return nil
+}
+
+// write stores the themes and only keeps them when that worked.
+func (t *themesType) write(stored storedThemes) error {
+	path, err := getThemesPath()
+	if err != nil {
+		return err
+	}
+	if err := writeJSONFile(path, stored); err != nil {
+		return fmt.Errorf("failed to write themes: %w", err)
+	}
+	t.stored = stored
+	return nil
+}
+
+func validateTheme(theme api.Theme) error {
+	switch {
+	case !themeName.MatchString(theme.Name):
+		return fmt.Errorf("%w: names may only contain letters, digits, - and _", ErrThemeInvalid)
+	case theme.FontFamily != "" && !cssFontFamily.MatchString(theme.FontFamily):
+		return fmt.Errorf("%w: font family may only contain letters, digits, spaces, quotes, commas, - and _", ErrThemeInvalid)
+	case theme.FontSize != "" && !cssLength.MatchString(theme.FontSize):
+		return fmt.Errorf("%w: font size has to be a CSS length like 4rem or 5vh", ErrThemeInvalid)
+	case len(theme.CSS) > api.ThemeMaxCSSSize:
+		return fmt.Errorf("%w: CSS is larger than %d bytes", ErrThemeInvalid, api.ThemeMaxCSSSize)
+	case strings.Contains(strings.ToLower(theme.CSS), "</style"):
+		return fmt.Errorf("%w: CSS may not end the style element", ErrThemeInvalid)
+	}
+
+	for name, color := range map[string]string{"background": theme.BackgroundColor, "foreground": theme.ForegroundColor, "accent": theme.AccentColor} {
+		if color != "" && !cssColor.MatchString(color) {
+			return fmt.Errorf("%w: %s color has to be a CSS color", ErrThemeInvalid, name)
+		}
+	}
+	for name, path := range map[string]string{"logo": theme.Logo, "background image": theme.BackgroundImage} {
+		if path == "" {
+			continue
+		}
+		if _, err := resolveImageURL(path, ErrThemeInvalid, ErrThemeNotFound); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveImageURL returns the URL of an image from the storage directory. The errors wrap
+// invalid or notFound, so that they match the feature that asked.
+func resolveImageURL(storagePath string, invalid error, notFound error) (string, error) {
+	path, exists, err := ResolveStorageFilePath(storagePath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("%w: file %s", notFound, storagePath)
+	}
+	mType, err := mimetype.DetectFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect mime type: %w", err)
+	}
+	if !isAnyType(mType, browserImageTypes) {
+		return "", fmt.Errorf("%w: %s is no image but %s", invalid, storagePath, mType.String())
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String(), nil
+}
+
+// activeThemeLook returns the active theme for the templates, an empty look for the built-in
+// one. Images that are gone since the theme was stored are left out.
+func activeThemeLook() themeLook {
+	themes.mutex.Lock()
+	defer themes.mutex.Unlock()
+
+	if err := themes.load(); err != nil {
+		slog.Warn("Failed to load themes, using the built-in look", "error", err)
+		return themeLook{}
+	}
+	theme, exists := themes.stored.Themes[themes.stored.Active]
+	if !exists {
+		return themeLook{}
+	}
+
+	look := themeLook{CSS: theme.CSS}
+	var variables strings.Builder
+	for _, variable := range []struct{ name, value string }{
+		{"--background-color", theme.BackgroundColor},
+		{"--foreground-color", theme.ForegroundColor},
+		{"--accent-color", theme.AccentColor},
+		{"--font-family", theme.FontFamily},
+		{"--font-size", theme.FontSize},
+	} {
+		if variable.value != "" {
+			fmt.Fprintf(&variables, "%s: %s; ", variable.name, variable.value)
+		}
+	}
+	if variables.Len() > 0 {
+		look.Variables = ":root { " + variables.String() + "}"
+	}
+
+	var err error
+	if theme.BackgroundImage != "" {
+		if look.BackgroundImage, err = resolveImageURL(theme.BackgroundImage, ErrThemeInvalid, ErrThemeNotFound); err != nil {
+			slog.Warn("Failed to load background image of theme", "theme", theme.Name, "error", err)
+		}
+	}
+	if theme.Logo != "" {
+		if look.Logo, err = resolveImageURL(theme.Logo, ErrThemeInvalid, ErrThemeNotFound); err != nil {
+			slog.Warn("Failed to load logo of theme", "theme", theme.Name, "error", err)
+		}
+	}
+	return look
+}
+
+// backgroundImageCSS covers the page with the background image of a theme.
+func backgroundImageCSS(imageURL string) string {
+	return `body { background-image: url("` + imageURL + `"); background-size: cover; background-position: center; }`
+}
@@ -0,0 +1,368 @@
+package pkg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrTranscodeBusy = errors.New("file is already queued for transcoding or the queue is full")
+var ErrFileTypeTranscodeNotSupported = errors.New("file type not supported for transcoding")
+
+// Finished jobs are only kept for the status, the oldest are dropped first.
+const maxFinishedTranscodeJobs = 50
+const transcodeQueueSize = 32
+
+// fallbackTranscodeHeight is used when the screen size can not be read from the browser.
+const fallbackTranscodeHeight = 1080
+
+var transcoder = transcoderType{queue: make(chan *api.TranscodeJob, transcodeQueueSize)}
+
+type transcoderType struct {
+	mutex sync.Mutex
+	jobs  []*api.TranscodeJob
+	queue chan *api.TranscodeJob
+	once  sync.Once
+}
+
+// Chromium plays H.264 in these profiles and AAC or MP3 audio in MP4 everywhere.
+var safeVideoProfiles = []string{"Baseline", "Constrained Baseline", "Main", "High"}
+var safeAudioCodecs = []string{"aac", "mp3"}
+
+// Images in these formats are converted to JPEG, or PNG if they have transparency.
+var convertedImageTypes = []string{"image/heic", "image/heif", "image/tiff", "image/bmp", "image/webp", "image/avif"}
+
+// QueueTranscode converts the file into a format the display can show, in the background.
+// Files that can already be shown are marked as skipped.
+func QueueTranscode(path string) (api.TranscodeJob, error) {
+	relativePath, err := storageRelativePath(path)
+	if err != nil {
+		return api.TranscodeJob{}, err
+	}
+
+	mType, err := mimetype.DetectFile(path)
+	if err != nil {
+		return api.TranscodeJob{}, fmt.Errorf("failed to detect mime type: %w", err)
+	}
+	if !strings.HasPrefix(mType.String(), "video/") && !strings.HasPrefix(mType.String(), "image/") {
+		return api.TranscodeJob{}, fmt.Errorf("%w: %s", ErrFileTypeTranscodeNotSupported, mType.String())
+	}
+
+	return transcoder.enqueue(relativePath)
+}
+
+// TranscodeStatus returns copies of the queued, running and recently finished jobs.
+func TranscodeStatus() []api.TranscodeJob {
+	transcoder.mutex.Lock()
+	defer transcoder.mutex.Unlock()
+
+	jobs := make([]api.TranscodeJob, 0, len(transcoder.jobs))
+	for _, job := range transcoder.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+func (t *transcoderType) enqueue(relativePath string) (api.TranscodeJob, error) {
+	t.once.Do(func() { go t.work() })
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, job := range t.jobs {
+		if job.Path == relativePath && (job.State == api.TranscodeQueued || job.State == api.TranscodeRunning) {
+			return api.TranscodeJob{}, ErrTranscodeBusy
+		}
+	}
+
+	job := &api.TranscodeJob{Path: relativePath, State: api.TranscodeQueued}
+	select {
+	case t.queue <- job:
+	default:
+		return api.TranscodeJob{}, ErrTranscodeBusy
+	}
+	t.jobs = append(t.jobs, job)
+	t.pruneFinished()
+
+	return *job, nil
+}
+
+func (t *transcoderType) pruneFinished() {
+	finished := 0
+	for i := len(t.jobs) - 1; i >= 0; i-- {
+		state := t.jobs[i].State
+		if state == api.TranscodeQueued || state == api.TranscodeRunning {
+			continue
+		}
+		finished++
+		if finished > maxFinishedTranscodeJobs {
+			t.jobs = slices.Delete(t.jobs, i, i+1)
+		}
+	}
+}
+
+// update changes a job while holding the lock, since the status is read concurrently.
+func (t *transcoderType) update(job *api.TranscodeJob, change func(job *api.TranscodeJob)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	change(job)
+}
+
+// work runs the jobs one after another, transcoding uses all cores anyway.
+func (t *transcoderType) work() {
+	for job := range t.queue {
+		t.update(job, func(job *api.TranscodeJob) { job.State = api.TranscodeRunning })
+
+		output, err := t.run(job)
+
+		t.update(job, func(job *api.TranscodeJob) {
+			switch {
+			case err != nil:
+				job.State = api.TranscodeFailed
+				job.Error = err.Error()
+			case output == "":
+				job.State = api.TranscodeSkipped
+			default:
+				job.State = api.TranscodeDone
+				job.Output = output
+				job.Progress = 1
+			}
+		})
+
+		if err != nil {
+			slog.Error("Failed to transcode file", "path", job.Path, "error", err)
+		} else if output != "" {
+			slog.Info("File transcoded", "path", job.Path, "output", output)
+			CleanCaches()
+		}
+	}
+}
+
+// run converts the file of the job and returns the storage-relative output path, which is
+// empty if nothing had to be done.
+func (t *transcoderType) run(job *api.TranscodeJob) (string, error) {
+	inputPath, exists, err := ResolveStorageFilePath(job.Path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", errors.New("file was removed before it could be transcoded")
+	}
+
+	mType, err := mimetype.DetectFile(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect mime type: %w", err)
+	}
+
+	var outputPath string
+	if strings.HasPrefix(mType.String(), "video/") {
+		outputPath, err = t.transcodeVideo(job, inputPath, mType)
+	} else {
+		outputPath, err = transcodeImage(inputPath, mType)
+	}
+	if err != nil || outputPath == "" {
+		return "", err
+	}
+
+	return storageRelativePath(outputPath)
+}
+
+func (t *transcoderType) transcodeVideo(job *api.TranscodeJob, inputPath string, mType *mimetype.MIME) (string, error) {
+	var meta api.FileMetaResponse
+	if err := readStreamMeta(inputPath, &meta); err != nil {
+		return "", err
+	}
+	if meta.VideoCodec == "" {
+		return "", errors.New("file has no video stream")
+	}
+
+	maxHeight := transcodeMaxHeight()
+	if isSafeVideo(mType, meta, maxHeight) {
+		return "", nil
+	}
+
+	outputPath := transcodeOutputPath(inputPath, ".mp4")
+	tempFile, err := os.CreateTemp(filepath.Dir(inputPath), ".transcode-*.mp4")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	// the height has to be even for yuv420p, -2 keeps the aspect ratio with an even width
+	scale := fmt.Sprintf("scale=-2:'min(%d,trunc(ih/2)*2)'", maxHeight)
+	cmd := exec.Command("ffmpeg", "-y", "-i", inputPath,
+		"-map", "0:v:0", "-map", "0:a:0?",
+		"-vf", scale, "-c:v", "libx264", "-profile:v", "high", "-pix_fmt", "yuv420p", "-preset", "medium", "-crf", "20",
+		"-c:a", "aac", "-b:a", "160k",
+		"-movflags", "+faststart", "-progress", "pipe:1", "-nostats", tempFile.Name())
+	err = runWithProgress(cmd, meta.Duration, func(progress float64) {
+		t.update(job, func(job *api.TranscodeJob) { job.Progress = progress })
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result api.FileMetaResponse
+	if err := readStreamMeta(tempFile.Name(), &result); err != nil {
+		return "", fmt.Errorf("failed to verify transcoded file: %w", err)
+	}
+	if result.VideoCodec != "h264" || math.Abs(result.Duration-meta.Duration) > max(1, meta.Duration*0.02) {
+		return "", fmt.Errorf("transcoded file is invalid: codec %s, duration %.1fs instead of %.1fs", result.VideoCodec, result.Duration, meta.Duration)
+	}
+
+	return outputPath, replaceOriginal(inputPath, tempFile.Name(), outputPath)
+}
+
+func isSafeVideo(mType *mimetype.MIME, meta api.FileMetaResponse, maxHeight int) bool {
+	return mType.Is("video/mp4") &&
+		meta.VideoCodec == "h264" &&
+		slices.Contains(safeVideoProfiles, meta.VideoProfile) &&
+		(meta.HasAudio == nil || !*meta.HasAudio || slices.Contains(safeAudioCodecs, meta.AudioCodec)) &&
+		meta.Height <= maxHeight
+}
+
+func transcodeMaxHeight() int {
+	if Config.TranscodeMaxHeight > 0 {
+		return Config.TranscodeMaxHeight
+	}
+	_, height, err := browser.Browser.ScreenResolution()
+	if err != nil || height <= 0 {
+		slog.Warn("Failed to read screen resolution, transcoding to the default height", "height", fallbackTranscodeHeight, "error", err)
+		return fallbackTranscodeHeight
+	}
+	return height
+}
+
+func transcodeImage(inputPath string, mType *mimetype.MIME) (string, error) {
+	if !slices.ContainsFunc(convertedImageTypes, mType.Is) {
+		return "", nil
+	}
+
+	// %A is the alpha channel, e.g. "False", "True" or "Blend"
+	alpha, err := runMetaTool(exec.Command("magick", "identify", "-format", "%A", inputPath+"[0]"))
+	if err != nil {
+		return "", err
+	}
+	ext := ".jpg"
+	if a := strings.TrimSpace(alpha); a != "False" && a != "Undefined" && a != "" {
+		ext = ".png"
+	}
+
+	outputPath := transcodeOutputPath(inputPath, ext)
+	tempFile, err := os.CreateTemp(filepath.Dir(inputPath), ".transcode-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	// orientation is applied, since the browser would otherwise have to know about it
+	cmd := exec.Command("magick", inputPath+"[0]", "-auto-orient", "-quality", "92", tempFile.Name())
+	if _, err := runMetaTool(cmd); err != nil {
+		return "", err
+	}
+
+	var result api.FileMetaResponse
+	if err := readImageMeta(tempFile.Name(), &result); err != nil || result.Width == 0 {
+		return "", fmt.Errorf("failed to verify converted image: %w", err)
+	}
+
+	return outputPath, replaceOriginal(inputPath, tempFile.Name(), outputPath)
+}
+
+// transcodeOutputPath keeps the name of the original with the new extension. If another
+// file already has that name, a number is added.
+func transcodeOutputPath(inputPath string, ext string) string {
+	base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+	outputPath := base + ext
+	for i := 1; ; i++ {
+		if outputPath == inputPath {
+			return outputPath
+		}
+		if _, err := os.Stat(outputPath); os.IsNotExist(err) {
+			return outputPath
+		}
+		outputPath = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// replaceOriginal moves the verified file into place and only then removes the original.
+func replaceOriginal(inputPath string, tempPath string, outputPath string) error {
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		return fmt.Errorf("failed to move transcoded file: %w", err)
+	}
+	if outputPath == inputPath {
+		return nil
+	}
+	if err := os.Remove(inputPath); err != nil {
+		return fmt.Errorf("failed to remove original file: %w", err)
+	}
+	return nil
+}
+
+// runWithProgress runs ffmpeg with "-progress pipe:1" and reports the share of the duration
+// that is done.
+func runWithProgress(cmd *exec.Cmd, duration float64, report func(progress float64)) error {
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return fmt.Errorf("%w: %s", errMetaToolMissing, cmd.Args[0])
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to read ffmpeg output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		// out_time_us is the position in the output in microseconds
+		raw, ok := strings.CutPrefix(scanner.Text(), "out_time_us=")
+		if !ok || duration <= 0 {
+			continue
+		}
+		if position, err := strconv.ParseFloat(raw, 64); err == nil {
+			report(min(max(position/1e6/duration, 0), 1))
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, lastLines(stderr.String(), 5))
+	}
+	return nil
+}
+
+func lastLines(text string, count int) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.Join(lines[max(len(lines)-count, 0):], "\n")
+}
+
+func storageRelativePath(path string) (string, error) {
+	storagePath, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := filepath.Rel(storagePath, path)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return "", ErrPathInvalid
+	}
+	return "/" + filepath.ToSlash(relativePath), nil
+}
@@ -0,0 +1,226 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"plg-mudics/display/browser"
+)
+
+var ErrUpdateInProgress = errors.New("another update is in progress")
+var ErrChecksumMismatch = errors.New("checksum does not match")
+var ErrSignatureInvalid = errors.New("signature is invalid")
+var ErrNoPreviousBinary = errors.New("no previous binary to roll back to")
+
+// The previous binary and the pending marker live next to the running binary.
+const previousBinarySuffix = ".previous"
+const pendingUpdateSuffix = ".pending"
+
+var updateMutex sync.Mutex
+
+// UpdatesEnabled reports whether an update token is configured.
+func UpdatesEnabled() bool {
+	return Config.UpdateToken != ""
+}
+
+// ApplyUpdate verifies the new binary and swaps it with the running one. The old binary
+// is kept for rollbacks. The new binary only runs after Restart.
+func ApplyUpdate(body io.Reader, checksum string, signature string) error {
+	if !updateMutex.TryLock() {
+		return ErrUpdateInProgress
+	}
+	defer updateMutex.Unlock()
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	// the temp file has to be on the same file system for the rename to be atomic
+	tempFile, err := os.CreateTemp(filepath.Dir(exe), ".plg-mudics-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tempFile, hash), body); err != nil {
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		return fmt.Errorf("failed to sync binary to disk: %w", err)
+	}
+
+	digest := hash.Sum(nil)
+	expected, err := hex.DecodeString(checksum)
+	if err != nil || !bytes.Equal(digest, expected) {
+		return ErrChecksumMismatch
+	}
+	if err := verifySignature(digest, signature); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tempFile.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+	// the marker is written first, so that a replaced binary is always rolled back if it
+	// is not confirmed
+	if err := os.WriteFile(exe+pendingUpdateSuffix, []byte("0"), 0644); err != nil {
+		return fmt.Errorf("failed to mark update as pending: %w", err)
+	}
+	if err := os.Rename(exe, exe+previousBinarySuffix); err != nil {
+		os.Remove(exe + pendingUpdateSuffix)
+		return fmt.Errorf("failed to keep previous binary: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), exe); err != nil {
+		os.Rename(exe+previousBinarySuffix, exe)
+		os.Remove(exe + pendingUpdateSuffix)
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+
+	slog.Info("Display binary replaced", "path", exe, "sha256", checksum)
+	return nil
+}
+
+// verifySignature checks the signature of digest with Config.UpdatePublicKey. Unsigned updates
+// are only accepted with Config.AllowUnsignedUpdates, signed ones only with a key to check them.
+func verifySignature(digest []byte, signature string) error {
+	rawKey := Config.UpdatePublicKey
+	if signature == "" {
+		if Config.AllowUnsignedUpdates {
+			return nil
+		}
+		return fmt.Errorf("%w: unsigned updates are not allowed", ErrSignatureInvalid)
+	}
+	if rawKey == "" {
+		return fmt.Errorf("%w: no public key is configured to check it", ErrSignatureInvalid)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(rawKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("configured public key is invalid")
+	}
+	rawSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(key, digest, rawSignature) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// ConfirmUpdate marks the running binary as healthy, so it is not rolled back on the next start.
+func ConfirmUpdate() error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(exe + pendingUpdateSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove pending marker: %w", err)
+	}
+	return nil
+}
+
+// RollbackUpdate restores the binary from before the last update. The caller is responsible
+// for calling Restart afterwards.
+func RollbackUpdate() error {
+	if !updateMutex.TryLock() {
+		return ErrUpdateInProgress
+	}
+	defer updateMutex.Unlock()
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(exe + previousBinarySuffix); err != nil {
+		return ErrNoPreviousBinary
+	}
+	if err := os.Rename(exe+previousBinarySuffix, exe); err != nil {
+		return fmt.Errorf("failed to restore previous binary: %w", err)
+	}
+	os.Remove(exe + pendingUpdateSuffix)
+
+	slog.Info("Display binary rolled back", "path", exe)
+	return nil
+}
+
+// CheckPendingUpdate has to be called on start. An update that is still not confirmed on
+// its second start is considered broken and rolled back.
+func CheckPendingUpdate() (bool, error) {
+	exe, err := executablePath()
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(exe + pendingUpdateSuffix)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read pending marker: %w", err)
+	}
+
+	starts, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	if starts < 1 {
+		err = os.WriteFile(exe+pendingUpdateSuffix, []byte(strconv.Itoa(starts+1)), 0644)
+		return false, err
+	}
+
+	return true, RollbackUpdate()
+}
+
+// restarting is set by Restart before the browser is closed, see Restarting.
+var restarting atomic.Bool
+
+// Restarting reports whether Restart closed the browser. main must not return then, as that
+// would exit the process before it is replaced.
+func Restarting() bool {
+	return restarting.Load()
+}
+
+// Restart closes all running programs and replaces this process with the binary on disk.
+// If replacing fails, the browser is already closed and the caller has to exit with an error.
+func Restart() error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	ResetView()
+	restarting.Store(true)
+	if err := browser.Browser.Close(); err != nil {
+		slog.Warn("Failed to close browser", "error", err)
+	}
+
+	return syscall.Exec(exe, os.Args, os.Environ())
+}
+
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	return exe, nil
+}
@@ -0,0 +1,190 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/shared/api"
+)
+
+var ErrWebsiteInvalid = errors.New("invalid website request")
+var ErrWebsiteBlocked = errors.New("website blocked")
+
+type websiteScriptConfig struct {
+	Zoom        float64 `json:"zoom"`
+	ScrollSpeed int     `json:"scrollSpeed"`
+	CSS         string  `json:"css"`
+}
+
+// OpenWebsite opens a website full screen with the kiosk options of the request. The allow
+// and deny lists are also checked for every page and frame the website navigates to.
+func OpenWebsite(request api.OpenWebsiteRequest) error {
+	parsed, err := url.Parse(request.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %s is no http or https URL", ErrWebsiteInvalid, request.URL)
+	}
+	if !websiteAllowed(request.URL) {
+		return fmt.Errorf("%w: %s", ErrWebsiteBlocked, parsed.Host)
+	}
+	if request.Zoom == 0 {
+		request.Zoom = 1
+	}
+	if err := validateWebsiteRequest(request); err != nil {
+		return err
+	}
+
+	config, err := json.Marshal(websiteScriptConfig{Zoom: request.Zoom, ScrollSpeed: request.ScrollSpeed, CSS: request.CSS})
+	if err != nil {
+		return fmt.Errorf("failed to encode website config: %w", err)
+	}
+	options := browser.WebsiteOptions{
+		Scripts:        []string{strings.Replace(websiteScript, "WEBSITE_CONFIG", string(config), 1)},
+		ReloadInterval: time.Duration(request.ReloadInterval) * time.Second,
+	}
+	if request.JS != "" {
+		// a script of its own, so that its syntax errors do not break the options
+		options.Scripts = append(options.Scripts, request.JS)
+	}
+	for _, cookie := range request.Cookies {
+		options.Cookies = append(options.Cookies, &network.CookieParam{
+			Name:   cookie.Name,
+			Value:  cookie.Value,
+			URL:    request.URL,
+			Domain: cookie.Domain,
+			Path:   cookie.Path,
+		})
+	}
+	if request.BasicAuth != nil {
+		options.Username = request.BasicAuth.Username
+		options.Password = request.BasicAuth.Password
+	}
+	if len(Config.WebsiteAllow) > 0 || len(Config.WebsiteDeny) > 0 {
+		options.Allowed = websiteAllowed
+	}
+
+	ResetView()
+	return browser.Browser.OpenPage(request.URL, options)
+}
+
+func validateWebsiteRequest(request api.OpenWebsiteRequest) error {
+	switch {
+	case request.Zoom < api.WebsiteMinZoom || request.Zoom > api.WebsiteMaxZoom:
+		return fmt.Errorf("%w: zoom has to be between %g and %g", ErrWebsiteInvalid, api.WebsiteMinZoom, float64(api.WebsiteMaxZoom))
+	case request.ReloadInterval < 0 || (request.ReloadInterval > 0 && request.ReloadInterval < api.WebsiteMinReloadInterval):
+		return fmt.Errorf("%w: reload interval has to be 0 or at least %d seconds", ErrWebsiteInvalid, api.WebsiteMinReloadInterval)
+	case request.ScrollSpeed < 0 || request.ScrollSpeed > api.WebsiteMaxScrollSpeed:
+		return fmt.Errorf("%w: scroll speed has to be between 0 and %d", ErrWebsiteInvalid, api.WebsiteMaxScrollSpeed)
+	case len(request.CSS)+len(request.JS) > api.WebsiteMaxInjectedSize:
+		return fmt.Errorf("%w: css and js are larger than %d bytes", ErrWebsiteInvalid, api.WebsiteMaxInjectedSize)
+	case request.BasicAuth != nil && request.BasicAuth.Username == "":
+		return fmt.Errorf("%w: basic auth needs a username", ErrWebsiteInvalid)
+	}
+	for _, cookie := range request.Cookies {
+		if cookie.Name == "" {
+			return fmt.Errorf("%w: cookies need a name", ErrWebsiteInvalid)
+		}
+	}
+	return nil
+}
+
+// websiteAllowed checks a URL against Config.WebsiteDeny and Config.WebsiteAllow. Only http and
+// https URLs are checked, e.g. about:blank frames are always allowed.
+func websiteAllowed(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return true
+	}
+
+	for _, pattern := range Config.WebsiteDeny {
+		if matchWebsitePattern(pattern, parsed) {
+			return false
+		}
+	}
+	if len(Config.WebsiteAllow) == 0 {
+		return true
+	}
+	for _, pattern := range Config.WebsiteAllow {
+		if matchWebsitePattern(pattern, parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWebsitePattern matches a host with its subdomains and an optional path prefix, e.g.
+// example.com/news matches https://www.example.com/news/today, but not /newsletter.
+func matchWebsitePattern(pattern string, parsed *url.URL) bool {
+	patternHost, patternPath, _ := strings.Cut(strings.ToLower(strings.TrimSpace(pattern)), "/")
+	host := strings.ToLower(parsed.Hostname())
+	if host != patternHost && !strings.HasSuffix(host, "."+patternHost) {
+		return false
+	}
+	if patternPath == "" {
+		return true
+	}
+	prefix := "/" + strings.TrimSuffix(patternPath, "/")
+	return parsed.Path == prefix || strings.HasPrefix(parsed.Path, prefix+"/")
+}
+
+// websiteScript applies the zoom, scroll speed and CSS of the request. Zoom and scrolling only
+// apply to the top frame, the CSS also to frames, where e.g. cookie banners often are.
+// WEBSITE_CONFIG is replaced with the websiteScriptConfig.
+const websiteScript = `(() => {
+	const config = WEBSITE_CONFIG;
+	const isTop = window.top === window;
+
+	let css = config.css;
+	if (isTop && config.zoom !== 1) {
+		css += '\nhtml { zoom: ' + config.zoom + ' !important; }';
+	}
+	if (css) {
+		// constructed style sheets also work on websites that forbid inline styles
+		try {
+			const sheet = new CSSStyleSheet();
+			sheet.replaceSync(css);
+			document.adoptedStyleSheets = [...document.adoptedStyleSheets, sheet];
+		} catch (error) {
+			console.error('Failed to apply CSS', error);
+		}
+	}
+
+	if (!isTop || config.scrollSpeed <= 0) {
+		return;
+	}
+	const pause = 3000;
+	window.addEventListener('load', () => {
+		let position = 0;
+		let last = performance.now();
+		let waitUntil = last + pause;
+		const step = (now) => {
+			const elapsed = now - last;
+			last = now;
+			if (now >= waitUntil) {
+				const end = document.documentElement.scrollHeight - window.innerHeight;
+				if (position >= end) {
+					// wait at the bottom, then at the top again
+					position = 0;
+					waitUntil = now + pause;
+				} else {
+					position = Math.min(end, position + (config.scrollSpeed * elapsed) / 1000);
+					if (position >= end) {
+						waitUntil = now + pause;
+					}
+				}
+				window.scrollTo(0, position);
+			}
+			requestAnimationFrame(step);
+		};
+		requestAnimationFrame(step);
+	});
+})();`
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func idleScreenRoute(ctx echo.Context) error {
+	screen, err := pkg.GetIdleScreen()
+	if err != nil {
+		slog.Error("Failed to read idle screen", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read idle screen"})
+	}
+	return ctx.JSON(http.StatusOK, screen)
+}
+
+func setIdleScreenRoute(ctx echo.Context) error {
+	var request api.IdleScreen
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse idle screen", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	screen, err := pkg.SetIdleScreen(request)
+	if err != nil {
+		slog.Error("Failed to set idle screen", "error", err)
+		return idleScreenErrorResponse(ctx, err, "Failed to set idle screen")
+	}
+
+	slog.Info("Idle screen changed", "kind", screen.Kind)
+	return ctx.JSON(http.StatusOK, screen)
+}
+
+func showIdleScreenRoute(ctx echo.Context) error {
+	if err := pkg.ShowIdleScreen(); err != nil {
+		slog.Error("Failed to show idle screen", "error", err)
+		return idleScreenErrorResponse(ctx, err, "Failed to show idle screen")
+	}
+
+	slog.Info("Idle screen shown")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func identifyRoute(ctx echo.Context) error {
+	var request api.IdentifyRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse identify request", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.Identify(request); err != nil {
+		slog.Error("Failed to identify display", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrIdentifyInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to identify display"})
+	}
+
+	slog.Info("Display identified", "number", request.Number, "name", request.Name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+// idleScreenErrorResponse describes what is wrong with the idle screen, other errors only get
+// the generic description.
+func idleScreenErrorResponse(ctx echo.Context, err error, description string) error {
+	status, code := pkgErrorStatus(err)
+	if errors.Is(err, pkg.ErrIdleScreenInvalid) || errors.Is(err, pkg.ErrIdleScreenNotFound) {
+		description = err.Error()
+	}
+	return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description})
+}
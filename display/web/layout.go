@@ -0,0 +1,91 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func layoutRoute(ctx echo.Context) error {
+	layout, err := pkg.GetLayout()
+	if err != nil {
+		slog.Error("Failed to read layout", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read layout"})
+	}
+	return ctx.JSON(http.StatusOK, layout)
+}
+
+func setLayoutRoute(ctx echo.Context) error {
+	var request api.Layout
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse layout", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.SetLayout(request); err != nil {
+		slog.Error("Failed to set layout", "error", err)
+		return layoutErrorResponse(ctx, err, "Failed to set layout")
+	}
+
+	slog.Info("Layout changed", "zones", len(request.Zones))
+	return ctx.JSON(http.StatusOK, request)
+}
+
+func showLayoutRoute(ctx echo.Context) error {
+	if err := pkg.ShowLayout(); err != nil {
+		slog.Error("Failed to show layout", "error", err)
+		return layoutErrorResponse(ctx, err, "Failed to show layout")
+	}
+
+	slog.Info("Layout shown")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func deleteLayoutRoute(ctx echo.Context) error {
+	if err := pkg.DeleteLayout(); err != nil {
+		slog.Error("Failed to remove layout", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to remove layout"})
+	}
+
+	slog.Info("Layout removed")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func setZoneRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid zone name"})
+	}
+
+	var request api.ZoneRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse zone", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	zone, err := pkg.SetZone(name, request)
+	if err != nil {
+		slog.Error("Failed to set zone", "zone", name, "error", err)
+		return layoutErrorResponse(ctx, err, "Failed to set zone")
+	}
+
+	slog.Info("Zone changed", "zone", name)
+	return ctx.JSON(http.StatusOK, zone)
+}
+
+// layoutErrorResponse describes what is wrong with the layout, other errors only get the
+// generic description.
+func layoutErrorResponse(ctx echo.Context, err error, description string) error {
+	status, code := pkgErrorStatus(err)
+	if errors.Is(err, pkg.ErrLayoutInvalid) || errors.Is(err, pkg.ErrLayoutNotFound) || errors.Is(err, pkg.ErrLayoutFileNotFound) || errors.Is(err, pkg.ErrFileTypeNotSupported) || errors.Is(err, pkg.ErrWebsiteBlocked) {
+		description = err.Error()
+	}
+	return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description})
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func macrosRoute(ctx echo.Context) error {
+	response, err := pkg.GetMacros()
+	if err != nil {
+		slog.Error("Failed to read macros", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read macros"})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func runMacroStepsRoute(ctx echo.Context) error {
+	var request api.Macro
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse macro", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.RunMacro(request.Steps); err != nil {
+		slog.Error("Failed to run macro", "error", err)
+		return macroErrorResponse(ctx, err, "Failed to run macro")
+	}
+
+	slog.Info("Macro run", "steps", len(request.Steps))
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func setMacroRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid macro name"})
+	}
+
+	var request api.Macro
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse macro", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+	request.Name = name
+
+	macro, err := pkg.SetMacro(request)
+	if err != nil {
+		slog.Error("Failed to set macro", "macro", name, "error", err)
+		return macroErrorResponse(ctx, err, "Failed to set macro")
+	}
+
+	slog.Info("Macro stored", "macro", name)
+	return ctx.JSON(http.StatusOK, macro)
+}
+
+func runMacroRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid macro name"})
+	}
+
+	if err := pkg.RunStoredMacro(name); err != nil {
+		slog.Error("Failed to run macro", "macro", name, "error", err)
+		return macroErrorResponse(ctx, err, "Failed to run macro")
+	}
+
+	slog.Info("Macro run", "macro", name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func deleteMacroRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid macro name"})
+	}
+
+	if err := pkg.DeleteMacro(name); err != nil {
+		slog.Error("Failed to remove macro", "macro", name, "error", err)
+		return macroErrorResponse(ctx, err, "Failed to remove macro")
+	}
+
+	slog.Info("Macro removed", "macro", name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+// macroErrorResponse describes what is wrong with the macro, other errors only get the
+// generic description.
+func macroErrorResponse(ctx echo.Context, err error, description string) error {
+	status, code := pkgErrorStatus(err)
+	if errors.Is(err, pkg.ErrMacroInvalid) || errors.Is(err, pkg.ErrMacroNotFound) || errors.Is(err, pkg.ErrKeyboardBusy) {
+		description = err.Error()
+	}
+	return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description})
+}
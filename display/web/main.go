@@ -11,6 +11,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	shared "plg-mudics/shared"
+	"plg-mudics/shared/api"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -19,31 +22,81 @@ import (
 	"plg-mudics/display/pkg"
 )
 
-func StartWebServer(port string) {
+func StartWebServer(address string) {
 	e := echo.New()
 
-	apiGroup := e.Group("/api")
+	apiGroup := e.Group(api.Prefix)
 	apiGroup.Use(middleware.CORS())
-	apiGroup.GET("/ping", pingRoute)
-	apiGroup.PATCH("/shellCommand", shellCommandRoute)
-	apiGroup.PATCH("/keyboardInput", keyboardInputRoute)
-	apiGroup.PATCH("/showHTML", showHTMLRoute)
-	apiGroup.PATCH("/takeScreenshot", takeScreenshotRoute)
-	apiGroup.PATCH("/openWebsite", openWebsiteRoute)
-
-	fileGroup := apiGroup.Group("/file")
-	fileGroup.Use(extractFilePathMiddleware)
-	fileGroup.POST("/:path", uploadFileRoute)
-	fileGroup.GET("/:path", downloadFileRoute)
-	fileGroup.PATCH("/:path", openFileRoute)
-	fileGroup.GET("/preview/:path", previewRoute)
-
-	err := e.Start(":" + port)
+	apiGroup.GET(api.PathPing, pingRoute)
+	apiGroup.GET(api.PathCapabilities, capabilitiesRoute)
+	apiGroup.GET(api.PathOpenAPI, openAPIRoute)
+	apiGroup.PATCH(api.PathShellCommand, shellCommandRoute, requireFeature(api.FeatureShellCommand))
+	apiGroup.PATCH(api.PathKeyboardInput, keyboardInputRoute, requireFeature(api.FeatureKeyboardInput))
+	apiGroup.PATCH(api.PathTypeText, typeTextRoute, requireFeature(api.FeatureTypeText))
+	apiGroup.GET(api.PathMacro, macrosRoute, requireFeature(api.FeatureMacro))
+	apiGroup.PATCH(api.PathMacro, runMacroStepsRoute, requireFeature(api.FeatureMacro))
+	apiGroup.PUT(api.PathMacroName, setMacroRoute, requireFeature(api.FeatureMacro))
+	apiGroup.PATCH(api.PathMacroName, runMacroRoute, requireFeature(api.FeatureMacro))
+	apiGroup.DELETE(api.PathMacroName, deleteMacroRoute, requireFeature(api.FeatureMacro))
+	apiGroup.PATCH(api.PathPointerInput, pointerInputRoute, requireFeature(api.FeaturePointerInput))
+	apiGroup.PATCH(api.PathShowHTML, showHTMLRoute, requireFeature(api.FeatureShowHTML))
+	apiGroup.PATCH(api.PathTakeScreenshot, takeScreenshotRoute, requireFeature(api.FeatureTakeScreenshot))
+	apiGroup.PATCH(api.PathOpenWebsite, openWebsiteRoute, requireFeature(api.FeatureOpenWebsite))
+	apiGroup.GET(api.PathDisplayOptions, displayOptionsRoute, requireFeature(api.FeatureDisplayOptions))
+	apiGroup.PUT(api.PathDisplayOptions, setDisplayOptionsRoute, requireFeature(api.FeatureDisplayOptions))
+	apiGroup.GET(api.PathOverlay, overlayRoute, requireFeature(api.FeatureOverlay))
+	apiGroup.PUT(api.PathOverlay, setOverlayRoute, requireFeature(api.FeatureOverlay))
+	apiGroup.DELETE(api.PathOverlay, clearOverlayRoute, requireFeature(api.FeatureOverlay))
+	apiGroup.GET(api.PathLayout, layoutRoute, requireFeature(api.FeatureLayout))
+	apiGroup.PUT(api.PathLayout, setLayoutRoute, requireFeature(api.FeatureLayout))
+	apiGroup.PATCH(api.PathLayout, showLayoutRoute, requireFeature(api.FeatureLayout))
+	apiGroup.DELETE(api.PathLayout, deleteLayoutRoute, requireFeature(api.FeatureLayout))
+	apiGroup.PUT(api.PathLayoutZone, setZoneRoute, requireFeature(api.FeatureLayout))
+	apiGroup.PATCH(api.PathTemplate, showTemplateRoute, requireFeature(api.FeatureTemplate))
+	apiGroup.GET(api.PathTheme, themesRoute, requireFeature(api.FeatureTheme))
+	apiGroup.DELETE(api.PathTheme, resetThemeRoute, requireFeature(api.FeatureTheme))
+	apiGroup.PUT(api.PathThemeName, setThemeRoute, requireFeature(api.FeatureTheme))
+	apiGroup.PATCH(api.PathThemeName, activateThemeRoute, requireFeature(api.FeatureTheme))
+	apiGroup.DELETE(api.PathThemeName, deleteThemeRoute, requireFeature(api.FeatureTheme))
+	apiGroup.GET(api.PathIdleScreen, idleScreenRoute, requireFeature(api.FeatureIdleScreen))
+	apiGroup.PUT(api.PathIdleScreen, setIdleScreenRoute, requireFeature(api.FeatureIdleScreen))
+	apiGroup.PATCH(api.PathIdleScreen, showIdleScreenRoute, requireFeature(api.FeatureIdleScreen))
+	apiGroup.PATCH(api.PathIdentify, identifyRoute, requireFeature(api.FeatureIdentify))
+	apiGroup.PATCH(api.PathScroll, scrollRoute, requireFeature(api.FeatureRemoteControl))
+	apiGroup.PATCH(api.PathZoom, zoomRoute, requireFeature(api.FeatureRemoteControl))
+	apiGroup.PATCH(api.PathClick, clickRoute, requireFeature(api.FeatureRemoteControl))
+	apiGroup.PATCH(api.PathNavigate, navigateRoute, requireFeature(api.FeatureRemoteControl))
+
+	apiGroup.POST(api.PathFile, uploadFileRoute, requireFeature(api.FeatureFileTransfer), extractFilePathMiddleware)
+	apiGroup.GET(api.PathFile, downloadFileRoute, requireFeature(api.FeatureFileTransfer), extractFilePathMiddleware)
+	apiGroup.PATCH(api.PathFile, openFileRoute, requireFeature(api.FeatureFileOpen), extractFilePathMiddleware)
+	apiGroup.GET(api.PathFilePreview, previewRoute, requireFeature(api.FeatureFilePreview), extractFilePathMiddleware)
+	apiGroup.GET(api.PathFileMeta, fileMetaRoute, requireFeature(api.FeatureFileMeta), extractFilePathMiddleware)
+	apiGroup.GET(api.PathTranscode, transcodeStatusRoute, requireFeature(api.FeatureTranscode))
+	apiGroup.POST(api.PathTranscodeFile, transcodeRoute, requireFeature(api.FeatureTranscode), extractFilePathMiddleware)
+
+	apiGroup.PUT(api.PathUpdate, updateRoute, requireFeature(api.FeatureUpdate), updateAuthMiddleware)
+	apiGroup.POST(api.PathUpdateConfirm, confirmUpdateRoute, requireFeature(api.FeatureUpdate), updateAuthMiddleware)
+	apiGroup.POST(api.PathUpdateRollback, rollbackUpdateRoute, requireFeature(api.FeatureUpdate), updateAuthMiddleware)
+
+	err := e.Start(address)
 	if err != nil {
 		slog.Error("Failed to start server", "error", err)
 	}
 }
 
+// requireFeature rejects requests to features that are turned off in the config.
+func requireFeature(feature api.Feature) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !pkg.Config.FeatureEnabled(feature) {
+				return ctx.JSON(http.StatusForbidden, shared.ErrorResponse{Code: shared.CodeFeatureDisabled, Description: "Feature is disabled on this display", Details: map[string]string{"feature": string(feature)}})
+			}
+			return next(ctx)
+		}
+	}
+}
+
 func extractFilePathMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(ctx echo.Context) error {
 		raw := ctx.Param("path")
@@ -51,13 +104,14 @@ func extractFilePathMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		decoded, err := url.PathUnescape(raw)
 		if err != nil {
 			slog.Warn("Invalid path encoding", "path", raw, "error", err)
-			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: "Invalid file path"})
+			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodePathInvalid, Description: "Invalid file path", Details: map[string]string{"path": raw}})
 		}
 
 		fullPath, exists, err := pkg.ResolveStorageFilePath(decoded)
 		if err != nil {
 			slog.Warn("Failed to validate file path", "path", decoded, "error", err)
-			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: "Invalid file path"})
+			status, code := pkgErrorStatus(err)
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Invalid file path", Details: map[string]string{"path": decoded}})
 		}
 
 		ctx.Set("fullPath", fullPath)
@@ -66,60 +120,106 @@ func extractFilePathMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-func shellCommandRoute(ctx echo.Context) error {
-	var commandInput struct {
-		Command string `json:"command"`
+// pkgErrorStatus maps the sentinel errors of pkg onto an HTTP status and error code.
+func pkgErrorStatus(err error) (int, shared.ErrorCode) {
+	switch {
+	case errors.Is(err, pkg.ErrPathInvalid), errors.Is(err, pkg.ErrPathIsDirectory), errors.Is(err, pkg.ErrPathIsNoDirectory):
+		return http.StatusBadRequest, shared.CodePathInvalid
+	case errors.Is(err, pkg.ErrFileTypeNotSupported), errors.Is(err, pkg.ErrFileTypePreviewNotSupported), errors.Is(err, pkg.ErrFileTypeTranscodeNotSupported):
+		return http.StatusUnsupportedMediaType, shared.CodeUnsupportedMediaType
+	case errors.Is(err, pkg.ErrPreviewOptionsInvalid), errors.Is(err, pkg.ErrDisplayOptionsInvalid),
+		errors.Is(err, pkg.ErrOverlayInvalid), errors.Is(err, pkg.ErrLayoutInvalid),
+		errors.Is(err, pkg.ErrTemplateInvalid), errors.Is(err, pkg.ErrThemeInvalid),
+		errors.Is(err, pkg.ErrIdleScreenInvalid), errors.Is(err, pkg.ErrIdentifyInvalid),
+		errors.Is(err, pkg.ErrWebsiteInvalid), errors.Is(err, pkg.ErrRemoteControlInvalid),
+		errors.Is(err, pkg.ErrPointerInputInvalid), errors.Is(err, pkg.ErrKeyUnknown),
+		errors.Is(err, pkg.ErrTypeTextInvalid), errors.Is(err, pkg.ErrMacroInvalid):
+		return http.StatusBadRequest, shared.CodeBadRequest
+	case errors.Is(err, pkg.ErrWebsiteBlocked):
+		return http.StatusForbidden, shared.CodeWebsiteBlocked
+	case errors.Is(err, browser.ErrNavigationFailed):
+		return http.StatusBadGateway, shared.CodeNavigationFailed
+	case errors.Is(err, browser.ErrNotAvailable):
+		return http.StatusConflict, shared.CodeNotAvailable
+	case errors.Is(err, pkg.ErrOverlayLogoNotFound), errors.Is(err, pkg.ErrLayoutFileNotFound):
+		return http.StatusNotFound, shared.CodeFileNotFound
+	case errors.Is(err, pkg.ErrLayoutNotFound), errors.Is(err, pkg.ErrTemplateNotFound),
+		errors.Is(err, pkg.ErrThemeNotFound), errors.Is(err, pkg.ErrIdleScreenNotFound),
+		errors.Is(err, pkg.ErrMacroNotFound):
+		return http.StatusNotFound, shared.CodeNotFound
+	case errors.Is(err, pkg.ErrFilePreviewToolsMissing):
+		return http.StatusInternalServerError, shared.CodePreviewToolsMissing
+	case errors.Is(err, pkg.ErrUpdateInProgress), errors.Is(err, pkg.ErrTranscodeBusy), errors.Is(err, pkg.ErrKeyboardBusy):
+		return http.StatusConflict, shared.CodeBusy
+	case errors.Is(err, pkg.ErrChecksumMismatch):
+		return http.StatusUnprocessableEntity, shared.CodeChecksumMismatch
+	case errors.Is(err, pkg.ErrSignatureInvalid):
+		return http.StatusUnprocessableEntity, shared.CodeSignatureInvalid
+	case errors.Is(err, pkg.ErrNoPreviousBinary):
+		return http.StatusNotFound, shared.CodeFileNotFound
+	default:
+		return http.StatusInternalServerError, shared.CodeInternal
 	}
+}
+
+func shellCommandRoute(ctx echo.Context) error {
+	var commandInput api.ShellCommandRequest
 	if err := ctx.Bind(&commandInput); err != nil {
 		slog.Error("Failed to parse shell command", "error", err)
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: shared.BadRequestDescription})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
 	}
 
 	cmd := exec.Command("bash", "-c", commandInput.Command)
-	storagePath, err := pkg.GetStoragePath()
+	workingDir, exists, err := pkg.ResolveStorageDirPath(commandInput.Dir)
 	if err != nil {
-		slog.Error("Failed to get storage path", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to get storage path"})
+		slog.Error("Failed to resolve working directory", "dir", commandInput.Dir, "error", err)
+		status, code := pkgErrorStatus(err)
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Invalid working directory", Details: map[string]string{"dir": commandInput.Dir}})
+	}
+	if !exists {
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "Working directory not found", Details: map[string]string{"dir": commandInput.Dir}})
 	}
-	cmd.Dir = storagePath
+	cmd.Dir = workingDir
 
 	commandOutput := shared.RunShellCommand(cmd)
 	if commandOutput.ExitCode != 0 {
 		slog.Error("Shell command execution error", "error", commandOutput.Stderr)
 	}
+	// files may have been renamed, changed or deleted
+	pkg.ScheduleCacheCleanup()
 
 	slog.Info("Shell command executed successfully", "command", commandInput.Command, "exitCode", commandOutput.ExitCode)
 	return ctx.JSON(http.StatusOK, commandOutput)
 }
 
 func keyboardInputRoute(ctx echo.Context) error {
-	var request struct {
-		Inputs []struct {
-			Key    string `json:"key"`
-			Action string `json:"action"`
-		} `json:"inputs"`
-	}
+	var request api.KeyboardInputRequest
 	if err := ctx.Bind(&request); err != nil {
 		slog.Error("Failed to parse keyboard input", "error", err)
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: shared.BadRequestDescription})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
 	}
 
 	var inputs []pkg.Input
 
 	for _, input := range request.Inputs {
-		if input.Action != "press" && input.Action != "release" {
+		if input.Action != api.KeyActionPress && input.Action != api.KeyActionRelease {
 			slog.Error("Invalid keyboard action", "action", input.Action)
-			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: fmt.Sprintf("Invalid action: %s", input.Action)})
+			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: fmt.Sprintf("Invalid action: %s", input.Action), Details: map[string]string{"action": string(input.Action)}})
 		}
 
 		var action pkg.KeyAction
-		if input.Action == "press" {
+		if input.Action == api.KeyActionPress {
 			action = pkg.KeyPress
 		}
-		if input.Action == "release" {
+		if input.Action == api.KeyActionRelease {
 			action = pkg.KeyRelease
 		}
 
+		if !pkg.KeyKnown(input.Key) {
+			slog.Error("Unknown keyboard key", "key", input.Key)
+			return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: fmt.Sprintf("Unknown key: %s", input.Key), Details: map[string]string{"key": input.Key}})
+		}
+
 		inputs = append(inputs, pkg.Input{
 			Key:    input.Key,
 			Action: action,
@@ -129,11 +229,59 @@ func keyboardInputRoute(ctx echo.Context) error {
 	err := pkg.KeyboardInput(inputs)
 	if err != nil {
 		slog.Error("Failed to send keyboard input", "inputs", inputs, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to send keyboard input"})
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrKeyboardBusy) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to send keyboard input"})
 	}
 
 	slog.Info("Keyboard input sent")
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func typeTextRoute(ctx echo.Context) error {
+	var request api.TypeTextRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse text", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	delay := api.TypeTextDefaultDelay
+	if request.Delay != nil {
+		delay = *request.Delay
+	}
+	if err := pkg.TypeText(request.Text, time.Duration(delay)*time.Millisecond); err != nil {
+		slog.Error("Failed to type text", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrTypeTextInvalid) || errors.Is(err, pkg.ErrKeyboardLayoutUnsupported) || errors.Is(err, pkg.ErrKeyboardBusy) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to type text"})
+	}
+
+	slog.Info("Text typed", "length", len([]rune(request.Text)))
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func pointerInputRoute(ctx echo.Context) error {
+	var request api.PointerInputRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse pointer input", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.PointerInput(request.Inputs); err != nil {
+		slog.Error("Failed to send pointer input", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrPointerInputInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to send pointer input"})
+	}
+
+	slog.Info("Pointer input sent")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
 
 func uploadFileRoute(ctx echo.Context) error {
@@ -144,17 +292,17 @@ func uploadFileRoute(ctx echo.Context) error {
 	// Ensure parent directories exist
 	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
 		slog.Error("Failed to create storage path", "error", err, "path", fullPath)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to prepare storage directory"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to prepare storage directory"})
 	}
 
 	if ctx.Get("fileExists").(bool) {
-		return ctx.JSON(http.StatusConflict, shared.ErrorResponse{Description: "File already exists"})
+		return ctx.JSON(http.StatusConflict, shared.ErrorResponse{Code: shared.CodeAlreadyExists, Description: "File already exists"})
 	}
 
 	file, err := os.Create(fullPath)
 	if err != nil {
 		slog.Error("Failed to create file", "file", fullPath, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to create file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to create file"})
 	}
 	defer func() {
 		fileCloseErr := file.Close()
@@ -169,24 +317,35 @@ func uploadFileRoute(ctx echo.Context) error {
 	_, err = io.Copy(file, ctx.Request().Body)
 	if err != nil {
 		slog.Error("Failed to write file", "file", fullPath, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to write file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to write file"})
 	}
 
 	err = file.Sync() // ensure data is flushed to disk
 	if err != nil {
 		slog.Error("Failed to sync file to disk", "file", fullPath, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to save file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to save file"})
+	}
+
+	pkg.ScheduleCacheCleanup()
+
+	if pkg.Config.PrerenderPresentations {
+		pkg.PrerenderPresentation(fullPath)
+	}
+	if pkg.Config.TranscodeUploads && pkg.Config.FeatureEnabled(api.FeatureTranscode) {
+		if _, err := pkg.QueueTranscode(fullPath); err != nil && !errors.Is(err, pkg.ErrFileTypeTranscodeNotSupported) {
+			slog.Warn("Failed to queue uploaded file for transcoding", "file", fullPath, "error", err)
+		}
 	}
 
 	slog.Info("File uploaded successfully", "path", fullPath)
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
 
 func downloadFileRoute(ctx echo.Context) error {
 	fullPath := ctx.Get("fullPath").(string)
 
 	if !ctx.Get("fileExists").(bool) {
-		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Description: "File not found"})
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "File not found"})
 	}
 
 	slog.Info("Serving file for download", "path", fullPath)
@@ -201,42 +360,63 @@ func openFileRoute(ctx echo.Context) error {
 	fullPath := ctx.Get("fullPath").(string)
 
 	if !ctx.Get("fileExists").(bool) {
-		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Description: "File not found"})
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "File not found"})
 	}
 
-	err = pkg.OpenFile(fullPath)
+	options, err := parseDisplayOptions(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: err.Error()})
+	}
+
+	err = pkg.OpenFile(fullPath, options)
 	if err != nil {
 		slog.Error("Failed to open file", "file", pathParam, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to open file"})
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrDisplayOptionsInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to open file"})
 	}
 
 	slog.Info("Successfully run file", "file", pathParam)
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
 
 func showHTMLRoute(ctx echo.Context) error {
-	var request struct {
-		HTML string `json:"html"`
-	}
+	var request api.ShowHTMLRequest
 	if err := ctx.Bind(&request); err != nil {
 		slog.Error("Failed to parse request", "error", err)
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: shared.BadRequestDescription})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
 	}
 
 	err := pkg.ShowHTML(request.HTML)
 	if err != nil {
 		slog.Error("Failed to open html", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to open html"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to open html"})
 	}
 
 	slog.Info("HTML content sent to client")
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
 
 func pingRoute(ctx echo.Context) error {
-	return ctx.JSON(http.StatusOK, struct {
-		Version string `json:"version"`
-	}{Version: shared.Version})
+	return ctx.JSON(http.StatusOK, api.PingResponse{Version: shared.Version})
+}
+
+func capabilitiesRoute(ctx echo.Context) error {
+	tools := pkg.AvailableTools()
+	return ctx.JSON(http.StatusOK, api.CapabilitiesResponse{
+		Version:    shared.Version,
+		APIVersion: api.APIVersion,
+		Features:   pkg.Features(tools),
+		Tools:      tools,
+	})
+}
+
+var openAPIDocument = api.OpenAPI("PLG MuDiCS Display", api.DisplayRoutes)
+
+func openAPIRoute(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, openAPIDocument)
 }
 
 func takeScreenshotRoute(ctx echo.Context) error {
@@ -245,13 +425,13 @@ func takeScreenshotRoute(ctx echo.Context) error {
 	screenshotPath, err := pkg.TakeScreenshot()
 	if err != nil {
 		slog.Error("Failed to take screenshot", "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to take screenshot"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to take screenshot"})
 	}
 
 	err = ctx.File(screenshotPath)
 	if err != nil {
 		slog.Error("Failed to serve file", "file", screenshotPath, "error", err)
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to serve file"})
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to serve file"})
 	}
 
 	return nil
@@ -261,36 +441,152 @@ func previewRoute(ctx echo.Context) error {
 	fullPath := ctx.Get("fullPath").(string)
 	exists := ctx.Get("fileExists").(bool)
 	if !exists {
-		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Description: "File not found"})
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "File not found"})
+	}
+
+	options, err := parsePreviewOptions(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: err.Error()})
 	}
 
-	outputFilePath, err := pkg.GenerateFilePreview(fullPath)
+	preview, err := pkg.GetFilePreview(ctx.Request().Context(), fullPath, options)
 	if err != nil {
 		slog.Error("Failed to generate preview", "file", fullPath, "error", err)
-		if errors.Is(err, pkg.ErrFileTypePreviewNotSupported) {
-			return ctx.JSON(http.StatusUnsupportedMediaType, shared.ErrorResponse{Description: "File type not supported for preview"})
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrPreviewOptionsInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
 		}
-		if errors.Is(err, pkg.ErrFilePreviewToolsMissing) {
-			return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Required tools for file preview are missing"})
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to generate preview"})
+	}
+
+	// the path stays the same when the file changes, so clients have to revalidate
+	ctx.Response().Header().Set("ETag", `"`+preview.ETag+`"`)
+	ctx.Response().Header().Set("Cache-Control", "no-cache")
+	return ctx.File(preview.Path)
+}
+
+func fileMetaRoute(ctx echo.Context) error {
+	fullPath := ctx.Get("fullPath").(string)
+	if !ctx.Get("fileExists").(bool) {
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "File not found"})
+	}
+
+	meta, err := pkg.GetFileMeta(fullPath)
+	if err != nil {
+		slog.Error("Failed to read file metadata", "file", fullPath, "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read file metadata"})
+	}
+
+	return ctx.JSON(http.StatusOK, meta)
+}
+
+func parsePreviewOptions(ctx echo.Context) (api.PreviewOptions, error) {
+	size, err := intQueryParam(ctx, api.QueryPreviewSize)
+	if err != nil {
+		return api.PreviewOptions{}, err
+	}
+	storyboard, err := intQueryParam(ctx, api.QueryPreviewStoryboard)
+	if err != nil {
+		return api.PreviewOptions{}, err
+	}
+
+	return api.PreviewOptions{
+		Size:       size,
+		Aspect:     ctx.QueryParam(api.QueryPreviewAspect),
+		Fit:        api.PreviewFit(ctx.QueryParam(api.QueryPreviewFit)),
+		Format:     api.PreviewFormat(ctx.QueryParam(api.QueryPreviewFormat)),
+		Storyboard: storyboard,
+	}, nil
+}
+
+// parseDisplayOptions reads the display options of opening a file. They are ignored when
+// the feature is disabled.
+func parseDisplayOptions(ctx echo.Context) (api.DisplayOptions, error) {
+	if !pkg.Config.FeatureEnabled(api.FeatureDisplayOptions) {
+		return api.DisplayOptions{}, nil
+	}
+
+	options := api.DisplayOptions{
+		Fit:        api.DisplayFit(ctx.QueryParam(api.QueryDisplayFit)),
+		Background: ctx.QueryParam(api.QueryDisplayBackground),
+		Align:      api.DisplayAlign(ctx.QueryParam(api.QueryDisplayAlign)),
+		Transition: api.DisplayTransition(ctx.QueryParam(api.QueryDisplayTransition)),
+	}
+	duration, err := intQueryParam(ctx, api.QueryDisplayTransitionDuration)
+	if err != nil {
+		return api.DisplayOptions{}, err
+	}
+	options.TransitionDuration = duration
+	if ctx.QueryParam(api.QueryDisplayRotation) != "" {
+		rotation, err := intQueryParam(ctx, api.QueryDisplayRotation)
+		if err != nil {
+			return api.DisplayOptions{}, err
 		}
-		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Description: "Failed to generate preview"})
+		options.Rotation = &rotation
 	}
+	return options, nil
+}
 
-	return ctx.File(outputFilePath)
+// intQueryParam returns 0 for a missing parameter.
+func intQueryParam(ctx echo.Context, name string) (int, error) {
+	raw := ctx.QueryParam(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s has to be a number", name)
+	}
+	return value, nil
 }
 
-func openWebsiteRoute(ctx echo.Context) error {
-	var request struct {
-		URL string `json:"url"`
+func displayOptionsRoute(ctx echo.Context) error {
+	options, err := pkg.GetDisplayDefaults()
+	if err != nil {
+		slog.Error("Failed to read display options", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read display options"})
 	}
+	return ctx.JSON(http.StatusOK, options)
+}
+
+func setDisplayOptionsRoute(ctx echo.Context) error {
+	var request api.DisplayOptions
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse display options", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.SetDisplayDefaults(request); err != nil {
+		slog.Error("Failed to store display options", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrDisplayOptionsInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to store display options"})
+	}
+
+	slog.Info("Display options changed")
+	return ctx.JSON(http.StatusOK, request)
+}
+
+func openWebsiteRoute(ctx echo.Context) error {
+	var request api.OpenWebsiteRequest
 	if err := ctx.Bind(&request); err != nil {
 		slog.Error("Failed to parse website input", "error", err)
-		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Description: shared.BadRequestDescription})
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
 	}
 
-	slog.Info("Opening url")
+	slog.Info("Opening url", "url", request.URL)
 
-	browser.Browser.OpenPage(request.URL)
+	if err := pkg.OpenWebsite(request); err != nil {
+		slog.Error("Failed to open website", "url", request.URL, "error", err)
+		status, code := pkgErrorStatus(err)
+		description := "Failed to open website"
+		if errors.Is(err, pkg.ErrWebsiteInvalid) || errors.Is(err, pkg.ErrWebsiteBlocked) || errors.Is(err, browser.ErrNavigationFailed) {
+			description = err.Error()
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description, Details: map[string]string{"url": request.URL}})
+	}
 
-	return ctx.JSON(http.StatusOK, struct{}{})
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
 }
@@ -0,0 +1,47 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func overlayRoute(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, pkg.GetOverlays())
+}
+
+func setOverlayRoute(ctx echo.Context) error {
+	var request api.Overlays
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse overlays", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.SetOverlays(request); err != nil {
+		slog.Error("Failed to set overlays", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrOverlayInvalid) || errors.Is(err, pkg.ErrOverlayLogoNotFound) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to set overlays"})
+	}
+
+	slog.Info("Overlays changed")
+	return ctx.JSON(http.StatusOK, request)
+}
+
+func clearOverlayRoute(ctx echo.Context) error {
+	if err := pkg.SetOverlays(api.Overlays{}); err != nil {
+		slog.Error("Failed to remove overlays", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to remove overlays"})
+	}
+
+	slog.Info("Overlays removed")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/browser"
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func scrollRoute(ctx echo.Context) error {
+	var request api.ScrollRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse scroll request", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.Scroll(request); err != nil {
+		slog.Error("Failed to scroll", "error", err)
+		return remoteControlErrorResponse(ctx, err, "Failed to scroll")
+	}
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func zoomRoute(ctx echo.Context) error {
+	var request api.ZoomRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse zoom request", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	factor, err := pkg.Zoom(request)
+	if err != nil {
+		slog.Error("Failed to zoom", "error", err)
+		return remoteControlErrorResponse(ctx, err, "Failed to zoom")
+	}
+
+	slog.Info("Zoom changed", "factor", factor)
+	return ctx.JSON(http.StatusOK, api.ZoomResponse{Factor: factor})
+}
+
+func clickRoute(ctx echo.Context) error {
+	var request api.ClickRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse click request", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.Click(request); err != nil {
+		slog.Error("Failed to click", "error", err)
+		return remoteControlErrorResponse(ctx, err, "Failed to click")
+	}
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func navigateRoute(ctx echo.Context) error {
+	var request api.NavigateRequest
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse navigate request", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.Navigate(request); err != nil {
+		slog.Error("Failed to navigate", "action", request.Action, "error", err)
+		return remoteControlErrorResponse(ctx, err, "Failed to navigate")
+	}
+
+	slog.Info("Navigated", "action", request.Action)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+// remoteControlErrorResponse describes invalid requests and why an action is not available,
+// other errors only get the generic description.
+func remoteControlErrorResponse(ctx echo.Context, err error, description string) error {
+	status, code := pkgErrorStatus(err)
+	if errors.Is(err, pkg.ErrRemoteControlInvalid) || errors.Is(err, browser.ErrNotAvailable) {
+		description = err.Error()
+	}
+	return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description})
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func showTemplateRoute(ctx echo.Context) error {
+	name := api.TemplateName(ctx.Param("name"))
+
+	var request api.TemplateParameters
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse template parameters", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+
+	if err := pkg.ShowTemplate(name, request); err != nil {
+		slog.Error("Failed to show template", "template", name, "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrTemplateInvalid) || errors.Is(err, pkg.ErrTemplateNotFound) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to show template"})
+	}
+
+	slog.Info("Template shown", "template", name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
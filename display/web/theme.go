@@ -0,0 +1,95 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func themesRoute(ctx echo.Context) error {
+	response, err := pkg.GetThemes()
+	if err != nil {
+		slog.Error("Failed to read themes", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to read themes"})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func resetThemeRoute(ctx echo.Context) error {
+	if err := pkg.ActivateTheme(""); err != nil {
+		slog.Error("Failed to reset theme", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to reset theme"})
+	}
+
+	slog.Info("Theme reset")
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func setThemeRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid theme name"})
+	}
+
+	var request api.Theme
+	if err := ctx.Bind(&request); err != nil {
+		slog.Error("Failed to parse theme", "error", err)
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: shared.BadRequestDescription})
+	}
+	request.Name = name
+
+	if err := pkg.SetTheme(request); err != nil {
+		slog.Error("Failed to set theme", "theme", name, "error", err)
+		return themeErrorResponse(ctx, err, "Failed to set theme")
+	}
+
+	slog.Info("Theme stored", "theme", name)
+	return ctx.JSON(http.StatusOK, request)
+}
+
+func activateThemeRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid theme name"})
+	}
+
+	if err := pkg.ActivateTheme(name); err != nil {
+		slog.Error("Failed to activate theme", "theme", name, "error", err)
+		return themeErrorResponse(ctx, err, "Failed to activate theme")
+	}
+
+	slog.Info("Theme activated", "theme", name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func deleteThemeRoute(ctx echo.Context) error {
+	name, err := url.PathUnescape(ctx.Param("name"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Invalid theme name"})
+	}
+
+	if err := pkg.DeleteTheme(name); err != nil {
+		slog.Error("Failed to remove theme", "theme", name, "error", err)
+		return themeErrorResponse(ctx, err, "Failed to remove theme")
+	}
+
+	slog.Info("Theme removed", "theme", name)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+// themeErrorResponse describes what is wrong with the theme, other errors only get the
+// generic description.
+func themeErrorResponse(ctx echo.Context, err error, description string) error {
+	status, code := pkgErrorStatus(err)
+	if errors.Is(err, pkg.ErrThemeInvalid) || errors.Is(err, pkg.ErrThemeNotFound) {
+		description = err.Error()
+	}
+	return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: description})
+}
@@ -0,0 +1,33 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+func transcodeStatusRoute(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, api.TranscodeStatusResponse{Jobs: pkg.TranscodeStatus()})
+}
+
+func transcodeRoute(ctx echo.Context) error {
+	fullPath := ctx.Get("fullPath").(string)
+	if !ctx.Get("fileExists").(bool) {
+		return ctx.JSON(http.StatusNotFound, shared.ErrorResponse{Code: shared.CodeFileNotFound, Description: "File not found"})
+	}
+
+	job, err := pkg.QueueTranscode(fullPath)
+	if err != nil {
+		slog.Error("Failed to queue file for transcoding", "file", fullPath, "error", err)
+		status, code := pkgErrorStatus(err)
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to queue file for transcoding"})
+	}
+
+	slog.Info("File queued for transcoding", "path", job.Path)
+	return ctx.JSON(http.StatusOK, job)
+}
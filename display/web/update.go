@@ -0,0 +1,92 @@
+package web
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"plg-mudics/display/pkg"
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+// restartDelay gives the response time to reach the client before the process is replaced.
+const restartDelay = 500 * time.Millisecond
+
+func updateAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		token := pkg.Config.UpdateToken
+		if token == "" {
+			return ctx.JSON(http.StatusForbidden, shared.ErrorResponse{Code: shared.CodeUnauthorized, Description: "Updates are disabled on this display"})
+		}
+
+		given, ok := strings.CutPrefix(ctx.Request().Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			slog.Warn("Rejected update request with wrong token", "remote", ctx.RealIP())
+			return ctx.JSON(http.StatusUnauthorized, shared.ErrorResponse{Code: shared.CodeUnauthorized, Description: "Invalid update token"})
+		}
+
+		return next(ctx)
+	}
+}
+
+func updateRoute(ctx echo.Context) error {
+	checksum := ctx.Request().Header.Get(api.HeaderUpdateSHA256)
+	signature := ctx.Request().Header.Get(api.HeaderUpdateSignature)
+	if checksum == "" {
+		return ctx.JSON(http.StatusBadRequest, shared.ErrorResponse{Code: shared.CodeBadRequest, Description: "Missing " + api.HeaderUpdateSHA256 + " header"})
+	}
+
+	err := pkg.ApplyUpdate(ctx.Request().Body, checksum, signature)
+	if err != nil {
+		slog.Error("Failed to apply update", "error", err)
+		status, code := pkgErrorStatus(err)
+		if errors.Is(err, pkg.ErrSignatureInvalid) {
+			return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: err.Error()})
+		}
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to apply update"})
+	}
+
+	restartLater()
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func confirmUpdateRoute(ctx echo.Context) error {
+	err := pkg.ConfirmUpdate()
+	if err != nil {
+		slog.Error("Failed to confirm update", "error", err)
+		return ctx.JSON(http.StatusInternalServerError, shared.ErrorResponse{Code: shared.CodeInternal, Description: "Failed to confirm update"})
+	}
+
+	slog.Info("Update confirmed", "version", shared.Version)
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func rollbackUpdateRoute(ctx echo.Context) error {
+	err := pkg.RollbackUpdate()
+	if err != nil {
+		slog.Error("Failed to roll back update", "error", err)
+		status, code := pkgErrorStatus(err)
+		return ctx.JSON(status, shared.ErrorResponse{Code: code, Description: "Failed to roll back update"})
+	}
+
+	restartLater()
+	return ctx.JSON(http.StatusOK, api.EmptyResponse{})
+}
+
+func restartLater() {
+	go func() {
+		time.Sleep(restartDelay)
+		slog.Info("Restarting display")
+		err := pkg.Restart()
+		slog.Error("Failed to restart", "error", err)
+		// the browser may be closed already, systemd starts the display again on failure
+		os.Exit(1)
+	}()
+}
@@ -133,6 +133,9 @@
       WorkingDirectory = "/home/mudics/mudics";
       User = "mudics";
       Type = "simple";
+      # a pushed update that crashes is rolled back on the next start
+      Restart = "on-failure";
+      RestartSec = 2;
     };
     environment = {
       DISPLAY = ":0";
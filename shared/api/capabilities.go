@@ -0,0 +1,124 @@
+package api
+
+// APIVersion is increased on every change to the display API that an older control
+// server or display can not handle. Version 1 is everything before the capabilities endpoint.
+const APIVersion = 2
+
+// Feature names a single action a display can perform. Features are only ever added,
+// so the control server can check for them instead of comparing versions.
+type Feature string
+
+const (
+	FeatureShellCommand         Feature = "shellCommand"
+	FeatureShellCommandDir      Feature = "shellCommand.dir"
+	FeatureKeyboardInput        Feature = "keyboardInput"
+	FeaturePointerInput         Feature = "pointerInput"
+	FeatureTypeText             Feature = "typeText"
+	FeatureMacro                Feature = "macro"
+	FeatureShowHTML             Feature = "showHTML"
+	FeatureTakeScreenshot       Feature = "takeScreenshot"
+	FeatureOpenWebsite          Feature = "openWebsite"
+	FeatureOpenWebsiteOptions   Feature = "openWebsite.options"
+	FeatureFileTransfer         Feature = "file.transfer"
+	FeatureFileOpen             Feature = "file.open"
+	FeatureFileOpenPresentation Feature = "file.open.presentation"
+	// FeatureFileOpenPresentationSlides means presentations are pre-rendered and shown in the browser.
+	FeatureFileOpenPresentationSlides Feature = "file.open.presentation.slides"
+	// FeatureFileOpenMedia covers WebM, Ogg, WebP, AVIF, SVG and audio files.
+	FeatureFileOpenMedia Feature = "file.open.media"
+	// FeatureFileOpenText covers plain text and Markdown files.
+	FeatureFileOpenText Feature = "file.open.text"
+	// FeatureFileOpenDocument covers office text documents, converted to PDF.
+	FeatureFileOpenDocument Feature = "file.open.document"
+	// FeatureFileOpenBundle covers ZIP files of static web pages.
+	FeatureFileOpenBundle          Feature = "file.open.bundle"
+	FeatureFilePreview             Feature = "file.preview"
+	FeatureFilePreviewPDF          Feature = "file.preview.pdf"
+	FeatureFilePreviewVideo        Feature = "file.preview.video"
+	FeatureFilePreviewAudio        Feature = "file.preview.audio"
+	FeatureFilePreviewPresentation Feature = "file.preview.presentation"
+	// FeatureFilePreviewOptions are the size, aspect, fit and format query parameters.
+	FeatureFilePreviewOptions    Feature = "file.preview.options"
+	FeatureFilePreviewStoryboard Feature = "file.preview.storyboard"
+	FeatureFileMeta              Feature = "file.meta"
+	FeatureTranscode             Feature = "transcode"
+	// FeatureDisplayOptions are the fit, rotation, background and align options for images and videos.
+	FeatureDisplayOptions Feature = "displayOptions"
+	// FeatureTransitions means local content is switched with transitions in a persistent player page.
+	FeatureTransitions Feature = "transitions"
+	FeatureOverlay     Feature = "overlay"
+	FeatureLayout      Feature = "layout"
+	// FeatureTemplate covers the title, bullets, countdown, stopwatch and clock templates.
+	FeatureTemplate   Feature = "template"
+	FeatureTheme      Feature = "theme"
+	FeatureIdleScreen Feature = "idleScreen"
+	FeatureIdentify   Feature = "identify"
+	// FeatureRemoteControl covers scrolling, zooming, clicking and navigating the shown content.
+	FeatureRemoteControl Feature = "remoteControl"
+	FeatureErrorCodes    Feature = "errorCodes"
+	FeatureUpdate        Feature = "update"
+)
+
+// LegacyFeatures are the features of displays that do not have the capabilities endpoint yet.
+var LegacyFeatures = []Feature{
+	FeatureShellCommand,
+	FeatureKeyboardInput,
+	FeatureShowHTML,
+	FeatureTakeScreenshot,
+	FeatureOpenWebsite,
+	FeatureFileTransfer,
+	FeatureFileOpen,
+	FeatureFileOpenPresentation,
+	FeatureFilePreview,
+	FeatureFilePreviewPDF,
+	FeatureFilePreviewVideo,
+}
+
+// External programs the display depends on for some features.
+const (
+	ToolMagick  = "magick"
+	ToolFFmpeg  = "ffmpeg"
+	ToolGS      = "gs"
+	ToolSoffice = "soffice"
+)
+
+type CapabilitiesResponse struct {
+	Version    string    `json:"version"`
+	APIVersion int       `json:"apiVersion"`
+	Features   []Feature `json:"features"`
+	// Tools maps the external programs to whether they are installed.
+	Tools map[string]bool `json:"tools,omitempty"`
+}
+
+func (c CapabilitiesResponse) Supports(feature Feature) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+type Compatibility string
+
+const (
+	Compatible      Compatibility = "compatible"
+	DisplayOutdated Compatibility = "display_outdated"
+	DisplayNewer    Compatibility = "display_newer"
+)
+
+func (Compatibility) EnumValues() []string {
+	return []string{string(Compatible), string(DisplayOutdated), string(DisplayNewer)}
+}
+
+// CheckCompatibility compares the API version of a display with the one of this build.
+func CheckCompatibility(capabilities CapabilitiesResponse) Compatibility {
+	switch {
+	case capabilities.APIVersion < APIVersion:
+		return DisplayOutdated
+	case capabilities.APIVersion > APIVersion:
+		return DisplayNewer
+	default:
+		return Compatible
+	}
+}
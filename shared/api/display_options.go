@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Query parameters of opening a file with PathFile. All of them are optional and override
+// the defaults of the display.
+const (
+	QueryDisplayFit        = "fit"
+	QueryDisplayRotation   = "rotation"
+	QueryDisplayBackground = "background"
+	QueryDisplayAlign      = "align"
+	QueryDisplayTransition = "transition"
+	// QueryDisplayTransitionDuration is in milliseconds.
+	QueryDisplayTransitionDuration = "transitionDuration"
+)
+
+// Values used when neither the request nor the display defaults set an option.
+const (
+	DisplayDefaultFit        = DisplayFitContain
+	DisplayDefaultBackground = "black"
+	DisplayDefaultAlign      = DisplayAlignCenter
+	DisplayDefaultTransition = DisplayTransitionCrossfade
+	// DisplayDefaultTransitionDuration and DisplayMaxTransitionDuration are in milliseconds.
+	DisplayDefaultTransitionDuration = 500
+	DisplayMaxTransitionDuration     = 10000
+)
+
+// DisplayBackgroundBlur fills the space around the media with a blurred copy of it.
+const DisplayBackgroundBlur = "blur"
+
+type DisplayFit string
+
+const (
+	// DisplayFitContain shows the whole media as large as possible.
+	DisplayFitContain DisplayFit = "contain"
+	// DisplayFitCover fills the screen and cuts off what does not fit.
+	DisplayFitCover DisplayFit = "cover"
+	// DisplayFitFill stretches the media to the screen, ignoring its aspect ratio.
+	DisplayFitFill DisplayFit = "fill"
+	// DisplayFitNone shows the media in its original size.
+	DisplayFitNone DisplayFit = "none"
+)
+
+func (DisplayFit) EnumValues() []string {
+	return []string{string(DisplayFitContain), string(DisplayFitCover), string(DisplayFitFill), string(DisplayFitNone)}
+}
+
+// DisplayAlign places the media when it does not fill the screen, or selects the part that
+// stays visible with DisplayFitCover.
+type DisplayAlign string
+
+const (
+	DisplayAlignCenter      DisplayAlign = "center"
+	DisplayAlignTop         DisplayAlign = "top"
+	DisplayAlignBottom      DisplayAlign = "bottom"
+	DisplayAlignLeft        DisplayAlign = "left"
+	DisplayAlignRight       DisplayAlign = "right"
+	DisplayAlignTopLeft     DisplayAlign = "top-left"
+	DisplayAlignTopRight    DisplayAlign = "top-right"
+	DisplayAlignBottomLeft  DisplayAlign = "bottom-left"
+	DisplayAlignBottomRight DisplayAlign = "bottom-right"
+)
+
+func (DisplayAlign) EnumValues() []string {
+	return []string{
+		string(DisplayAlignCenter), string(DisplayAlignTop), string(DisplayAlignBottom), string(DisplayAlignLeft), string(DisplayAlignRight),
+		string(DisplayAlignTopLeft), string(DisplayAlignTopRight), string(DisplayAlignBottomLeft), string(DisplayAlignBottomRight),
+	}
+}
+
+// DisplayTransition is how the display switches from the shown content to the next one.
+// Websites and programs like soffice are always switched to without transition.
+type DisplayTransition string
+
+const (
+	DisplayTransitionCut       DisplayTransition = "cut"
+	DisplayTransitionCrossfade DisplayTransition = "crossfade"
+	// DisplayTransitionFade fades the old content out to black and the new one in.
+	DisplayTransitionFade DisplayTransition = "fade"
+)
+
+func (DisplayTransition) EnumValues() []string {
+	return []string{string(DisplayTransitionCut), string(DisplayTransitionCrossfade), string(DisplayTransitionFade)}
+}
+
+// DisplayOptions control how content is shown. Fit, rotation, background and align apply to
+// images and videos. Empty fields fall back to the defaults of the display, and then to
+// contain, no rotation, black, center and a crossfade of 500ms.
+type DisplayOptions struct {
+	Fit DisplayFit `json:"fit,omitempty"`
+	// Rotation is clockwise in degrees, 0, 90, 180 or 270, for screens mounted in portrait.
+	Rotation *int `json:"rotation,omitempty"`
+	// Background is a CSS color, e.g. "#202020", or DisplayBackgroundBlur.
+	Background string            `json:"background,omitempty"`
+	Align      DisplayAlign      `json:"align,omitempty"`
+	Transition DisplayTransition `json:"transition,omitempty"`
+	// TransitionDuration is in milliseconds.
+	TransitionDuration int `json:"transitionDuration,omitempty"`
+}
+
+// Merge returns the options with the fields that are set in override replaced.
+func (o DisplayOptions) Merge(override DisplayOptions) DisplayOptions {
+	if override.Fit != "" {
+		o.Fit = override.Fit
+	}
+	if override.Rotation != nil {
+		o.Rotation = override.Rotation
+	}
+	if override.Background != "" {
+		o.Background = override.Background
+	}
+	if override.Align != "" {
+		o.Align = override.Align
+	}
+	if override.Transition != "" {
+		o.Transition = override.Transition
+	}
+	if override.TransitionDuration != 0 {
+		o.TransitionDuration = override.TransitionDuration
+	}
+	return o
+}
+
+// Query encodes the options that are set.
+func (o DisplayOptions) Query() url.Values {
+	query := url.Values{}
+	if o.Fit != "" {
+		query.Set(QueryDisplayFit, string(o.Fit))
+	}
+	if o.Rotation != nil {
+		query.Set(QueryDisplayRotation, strconv.Itoa(*o.Rotation))
+	}
+	if o.Background != "" {
+		query.Set(QueryDisplayBackground, o.Background)
+	}
+	if o.Align != "" {
+		query.Set(QueryDisplayAlign, string(o.Align))
+	}
+	if o.Transition != "" {
+		query.Set(QueryDisplayTransition, string(o.Transition))
+	}
+	if o.TransitionDuration != 0 {
+		query.Set(QueryDisplayTransitionDuration, strconv.Itoa(o.TransitionDuration))
+	}
+	return query
+}
@@ -0,0 +1,43 @@
+package api
+
+// IdleScreenKind is what the display shows after starting and with PathIdleScreen.
+type IdleScreenKind string
+
+const (
+	// IdleScreenInfo shows the IP and MAC address, and a QR code of the control server.
+	IdleScreenInfo  IdleScreenKind = "info"
+	IdleScreenImage IdleScreenKind = "image"
+	IdleScreenHTML  IdleScreenKind = "html"
+)
+
+func (IdleScreenKind) EnumValues() []string {
+	return []string{string(IdleScreenInfo), string(IdleScreenImage), string(IdleScreenHTML)}
+}
+
+type IdleScreen struct {
+	// Kind defaults to IdleScreenInfo.
+	Kind IdleScreenKind `json:"kind,omitempty"`
+	// Image is the storage-relative path of the image of IdleScreenImage.
+	Image string `json:"image,omitempty"`
+	// HTML is shown full screen for IdleScreenHTML.
+	HTML string `json:"html,omitempty"`
+	// ControlURL is shown as QR code by IdleScreenInfo. Without it, the QR code only shows
+	// up when a control server runs on the display itself.
+	ControlURL string `json:"controlUrl,omitempty"`
+}
+
+// Limits of PathIdentify, in seconds.
+const (
+	IdentifyDefaultDuration = 10
+	IdentifyMaxDuration     = 300
+)
+
+// IdentifyRequest shows on top of everything which entry of the control server a display is.
+type IdentifyRequest struct {
+	// Number is shown large, e.g. the position of the display in the control server.
+	Number int    `json:"number"`
+	Name   string `json:"name,omitempty"`
+	Group  string `json:"group,omitempty"`
+	// Duration is in seconds, defaults to IdentifyDefaultDuration.
+	Duration int `json:"duration,omitempty"`
+}
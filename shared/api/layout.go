@@ -0,0 +1,47 @@
+package api
+
+// Limits of layouts.
+const (
+	LayoutMaxZones = 16
+	LayoutMaxItems = 100
+	// LayoutDefaultItemDuration is in seconds.
+	LayoutDefaultItemDuration = 10
+)
+
+// Layout splits the screen into zones that show content independently of each other.
+type Layout struct {
+	Zones []Zone `json:"zones"`
+}
+
+// Zone is a rectangle of the screen. The position and size are in percent of the screen,
+// later zones are drawn on top of earlier ones.
+type Zone struct {
+	// Name addresses the zone in PathLayoutZone, it may contain letters, digits, - and _.
+	Name   string  `json:"name"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	// Fit is how images and videos fill the zone, defaults to DisplayFitContain.
+	Fit DisplayFit `json:"fit,omitempty"`
+	// Items are shown one after another and repeated, a single item stays.
+	Items []ZoneItem `json:"items"`
+}
+
+// ZoneItem is one of a file, HTML or a website.
+type ZoneItem struct {
+	// File is the storage-relative path of an image, video or PDF.
+	File string `json:"file,omitempty"`
+	HTML string `json:"html,omitempty"`
+	// URL is an http or https URL. Many websites refuse to be shown in a zone.
+	URL string `json:"url,omitempty"`
+	// Duration is in seconds, defaults to LayoutDefaultItemDuration. Videos without a
+	// duration play until they end.
+	Duration int `json:"duration,omitempty"`
+}
+
+// ZoneRequest replaces the items of a single zone with PathLayoutZone.
+type ZoneRequest struct {
+	Fit   DisplayFit `json:"fit,omitempty"`
+	Items []ZoneItem `json:"items"`
+}
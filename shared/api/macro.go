@@ -0,0 +1,50 @@
+package api
+
+type MacroStepAction string
+
+const (
+	// MacroTap presses the keys, holds them for Hold and releases them.
+	MacroTap     MacroStepAction = "tap"
+	MacroPress   MacroStepAction = "press"
+	MacroRelease MacroStepAction = "release"
+)
+
+func (MacroStepAction) EnumValues() []string {
+	return []string{string(MacroTap), string(MacroPress), string(MacroRelease)}
+}
+
+// Limits of Macro, durations are in milliseconds.
+const (
+	MacroMaxNameLength = 100
+	MacroMaxSteps      = 100
+	MacroMaxKeys       = 8
+	MacroMaxRepeat     = 100
+	MacroMaxStepTime   = 10000
+	// MacroMaxDuration limits the time a whole macro takes.
+	MacroMaxDuration = 60000
+)
+
+// MacroStep presses, releases or taps a key or a chord of keys, e.g. Control and R. Keys are
+// named like in KeyboardInput.
+type MacroStep struct {
+	Keys []string `json:"keys"`
+	// Action defaults to MacroTap.
+	Action MacroStepAction `json:"action,omitempty"`
+	// Hold is how long MacroTap holds the keys, in milliseconds.
+	Hold int `json:"hold,omitempty"`
+	// Repeat runs the step this many times, defaults to 1.
+	Repeat int `json:"repeat,omitempty"`
+	// Delay is the pause after each run of the step, in milliseconds.
+	Delay int `json:"delay,omitempty"`
+}
+
+// Macro is a named sequence of key presses, e.g. "PowerPoint: end show".
+type Macro struct {
+	// Name addresses the macro in PathMacroName. It may not contain control characters or /.
+	Name  string      `json:"name"`
+	Steps []MacroStep `json:"steps"`
+}
+
+type MacrosResponse struct {
+	Macros []Macro `json:"macros"`
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"plg-mudics/shared"
+)
+
+// Enum is implemented by string types that only allow a fixed set of values.
+type Enum interface {
+	EnumValues() []string
+}
+
+var pathParamRegex = regexp.MustCompile(`:([a-zA-Z]+)`)
+
+// OpenAPI generates an OpenAPI 3 document describing the given routes.
+// The result can be passed directly to ctx.JSON.
+func OpenAPI(title string, routes []Route) map[string]any {
+	paths := map[string]any{}
+
+	for _, route := range routes {
+		path := Prefix + pathParamRegex.ReplaceAllString(route.Path, "{$1}")
+		operations, ok := paths[path].(map[string]any)
+		if !ok {
+			operations = map[string]any{}
+			paths[path] = operations
+		}
+		operations[strings.ToLower(route.Method)] = operation(route)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   title,
+			"version": shared.Version,
+		},
+		"paths": paths,
+	}
+}
+
+func operation(route Route) map[string]any {
+	op := map[string]any{
+		"summary": route.Summary,
+	}
+
+	parameters := []any{}
+	for _, match := range pathParamRegex.FindAllStringSubmatch(route.Path, -1) {
+		parameters = append(parameters, map[string]any{
+			"name":        match[1],
+			"in":          "path",
+			"required":    true,
+			"description": "Storage-relative path, URL encoded.",
+			"schema":      map[string]any{"type": "string"},
+		})
+	}
+	for name, description := range route.Query {
+		parameters = append(parameters, map[string]any{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"schema":      map[string]any{"type": "string"},
+		})
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	if body := content(route.Request, route.RequestContentType); body != nil {
+		op["requestBody"] = map[string]any{"required": true, "content": body}
+	}
+
+	responses := map[string]any{}
+	success := map[string]any{"description": "Success"}
+	if body := content(route.Response, route.ResponseContentType); body != nil {
+		success["content"] = body
+	}
+	responses["200"] = success
+
+	errors := map[int]string{
+		http.StatusBadRequest:          shared.BadRequestDescription,
+		http.StatusInternalServerError: "Something (undefined) on the server side has gone very wrong.",
+	}
+	for status, description := range route.Errors {
+		errors[status] = description
+	}
+	for status, description := range errors {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": description,
+			"content":     content(ErrorResponse{}, ""),
+		}
+	}
+	op["responses"] = responses
+
+	return op
+}
+
+func content(body any, contentType string) map[string]any {
+	if contentType != "" {
+		return map[string]any{
+			contentType: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema(reflect.TypeOf(body))},
+	}
+}
+
+var enumType = reflect.TypeFor[Enum]()
+
+func schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(enumType) {
+		values := reflect.Zero(t).Interface().(Enum).EnumValues()
+		return map[string]any{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := structSchema(field.Type)
+			for key, value := range embedded["properties"].(map[string]any) {
+				properties[key] = value
+			}
+			if embeddedRequired, ok := embedded["required"].([]string); ok {
+				required = append(required, embeddedRequired...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	result := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
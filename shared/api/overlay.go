@@ -0,0 +1,85 @@
+package api
+
+// OverlayCorner places an overlay in a corner of the screen.
+type OverlayCorner string
+
+const (
+	OverlayTopLeft     OverlayCorner = "top-left"
+	OverlayTopRight    OverlayCorner = "top-right"
+	OverlayBottomLeft  OverlayCorner = "bottom-left"
+	OverlayBottomRight OverlayCorner = "bottom-right"
+)
+
+func (OverlayCorner) EnumValues() []string {
+	return []string{string(OverlayTopLeft), string(OverlayTopRight), string(OverlayBottomLeft), string(OverlayBottomRight)}
+}
+
+// OverlayEdge places the ticker at the top or bottom of the screen.
+type OverlayEdge string
+
+const (
+	OverlayTop    OverlayEdge = "top"
+	OverlayBottom OverlayEdge = "bottom"
+)
+
+func (OverlayEdge) EnumValues() []string {
+	return []string{string(OverlayTop), string(OverlayBottom)}
+}
+
+// Limits of the overlay options.
+const (
+	OverlayDefaultTickerSpeed = 120
+	OverlayMaxTickerSpeed     = 1000
+	OverlayDefaultLogoHeight  = 10
+	// OverlayMaxLogoSize is the size of the logo file in bytes.
+	OverlayMaxLogoSize = 2 << 20
+)
+
+// Overlays are shown on top of all content of the browser and stay when the content changes.
+// Every overlay is optional, nil removes it.
+type Overlays struct {
+	Clock      *ClockOverlay      `json:"clock,omitempty"`
+	Ticker     *TickerOverlay     `json:"ticker,omitempty"`
+	Logo       *LogoOverlay       `json:"logo,omitempty"`
+	LowerThird *LowerThirdOverlay `json:"lowerThird,omitempty"`
+	Countdown  *CountdownOverlay  `json:"countdown,omitempty"`
+}
+
+type ClockOverlay struct {
+	// Corner defaults to OverlayTopRight.
+	Corner  OverlayCorner `json:"corner,omitempty"`
+	Seconds bool          `json:"seconds,omitempty"`
+}
+
+type TickerOverlay struct {
+	Text string `json:"text"`
+	// Edge defaults to OverlayBottom.
+	Edge OverlayEdge `json:"edge,omitempty"`
+	// Speed is in pixels per second, defaults to OverlayDefaultTickerSpeed.
+	Speed int `json:"speed,omitempty"`
+}
+
+type LogoOverlay struct {
+	// Path is the storage-relative path of an image.
+	Path string `json:"path"`
+	// Corner defaults to OverlayTopLeft.
+	Corner OverlayCorner `json:"corner,omitempty"`
+	// Height is in percent of the screen height, defaults to OverlayDefaultLogoHeight.
+	Height int `json:"height,omitempty"`
+}
+
+// LowerThirdOverlay is a text bar in the lower left, e.g. for the name of a speaker.
+type LowerThirdOverlay struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type CountdownOverlay struct {
+	// Until is the end of the countdown in RFC 3339, e.g. "2026-05-04T18:00:00+02:00".
+	Until string `json:"until"`
+	Label string `json:"label,omitempty"`
+	// FinishedText replaces the time when the countdown is over.
+	FinishedText string `json:"finishedText,omitempty"`
+	// Corner defaults to OverlayBottomRight.
+	Corner OverlayCorner `json:"corner,omitempty"`
+}
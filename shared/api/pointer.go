@@ -0,0 +1,48 @@
+package api
+
+type PointerAction string
+
+const (
+	// PointerMove moves to X and Y, or by them with Relative.
+	PointerMove        PointerAction = "move"
+	PointerPress       PointerAction = "press"
+	PointerRelease     PointerAction = "release"
+	PointerClick       PointerAction = "click"
+	PointerDoubleClick PointerAction = "doubleClick"
+	// PointerScroll turns the wheel by DeltaX and DeltaY.
+	PointerScroll PointerAction = "scroll"
+)
+
+func (PointerAction) EnumValues() []string {
+	return []string{
+		string(PointerMove),
+		string(PointerPress),
+		string(PointerRelease),
+		string(PointerClick),
+		string(PointerDoubleClick),
+		string(PointerScroll),
+	}
+}
+
+// PointerMaxScroll limits PointerInput.DeltaX and DeltaY, in wheel notches.
+const PointerMaxScroll = 100
+
+// PointerInput is a single mouse event on the display, outside of the browser too, e.g. in
+// the dialogs of soffice.
+type PointerInput struct {
+	Action PointerAction `json:"action"`
+	// X and Y are normalized from 0, the left or top edge, to 1, the right or bottom edge.
+	// With Relative, they are an offset from -1 to 1 instead.
+	X        float64 `json:"x,omitempty"`
+	Y        float64 `json:"y,omitempty"`
+	Relative bool    `json:"relative,omitempty"`
+	// Button is used by press, release, click and doubleClick, defaults to MouseButtonLeft.
+	Button MouseButton `json:"button,omitempty"`
+	// DeltaX and DeltaY are wheel notches, positive scrolls right and down.
+	DeltaX int `json:"deltaX,omitempty"`
+	DeltaY int `json:"deltaY,omitempty"`
+}
+
+type PointerInputRequest struct {
+	Inputs []PointerInput `json:"inputs"`
+}
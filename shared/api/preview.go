@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Query parameters of PathFilePreview. All of them are optional.
+const (
+	QueryPreviewSize       = "size"
+	QueryPreviewAspect     = "aspect"
+	QueryPreviewFit        = "fit"
+	QueryPreviewFormat     = "format"
+	QueryPreviewStoryboard = "storyboard"
+)
+
+// Limits of the preview query parameters.
+const (
+	PreviewDefaultSize   = 100
+	PreviewMinSize       = 16
+	PreviewMaxSize       = 1920
+	PreviewMaxStoryboard = 50
+	PreviewDefaultAspect = "1:1"
+	PreviewDefaultFit    = PreviewFitCrop
+	PreviewDefaultFormat = PreviewFormatWebP
+	// PreviewMaxWidthWebP and PreviewMaxWidthJPEG limit the width of a whole storyboard, as
+	// the encoders can not write wider images.
+	PreviewMaxWidthWebP = 16383
+	PreviewMaxWidthJPEG = 65535
+)
+
+type PreviewFit string
+
+const (
+	// PreviewFitCrop fills the whole preview and cuts off what does not fit, centered.
+	PreviewFitCrop PreviewFit = "crop"
+	// PreviewFitContain scales the file to fit into the preview, keeping all of it visible.
+	PreviewFitContain PreviewFit = "contain"
+)
+
+func (PreviewFit) EnumValues() []string {
+	return []string{string(PreviewFitCrop), string(PreviewFitContain)}
+}
+
+type PreviewFormat string
+
+const (
+	PreviewFormatWebP PreviewFormat = "webp"
+	PreviewFormatJPEG PreviewFormat = "jpeg"
+	PreviewFormatPNG  PreviewFormat = "png"
+)
+
+func (PreviewFormat) EnumValues() []string {
+	return []string{string(PreviewFormatWebP), string(PreviewFormatJPEG), string(PreviewFormatPNG)}
+}
+
+// PreviewOptions select the variant of a preview. Zero values fall back to the defaults,
+// which are a 100px square WebP.
+type PreviewOptions struct {
+	// Size is the width of the preview, or of a single frame of a storyboard, in pixels.
+	Size int
+	// Aspect is the ratio of width to height, e.g. "16:9".
+	Aspect string
+	Fit    PreviewFit
+	Format PreviewFormat
+	// Storyboard is the number of video frames spread over the duration, placed next to
+	// each other in a single image. Zero returns a single frame. Size times Storyboard is
+	// limited by PreviewMaxWidthWebP and PreviewMaxWidthJPEG.
+	Storyboard int
+}
+
+// Query encodes the options that differ from the defaults.
+func (o PreviewOptions) Query() url.Values {
+	query := url.Values{}
+	if o.Size != 0 {
+		query.Set(QueryPreviewSize, strconv.Itoa(o.Size))
+	}
+	if o.Aspect != "" {
+		query.Set(QueryPreviewAspect, o.Aspect)
+	}
+	if o.Fit != "" {
+		query.Set(QueryPreviewFit, string(o.Fit))
+	}
+	if o.Format != "" {
+		query.Set(QueryPreviewFormat, string(o.Format))
+	}
+	if o.Storyboard != 0 {
+		query.Set(QueryPreviewStoryboard, strconv.Itoa(o.Storyboard))
+	}
+	return query
+}
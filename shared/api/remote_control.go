@@ -0,0 +1,99 @@
+package api
+
+// ScrollRequest scrolls the shown content, either By an offset or To a position.
+type ScrollRequest struct {
+	// By scrolls like a mouse wheel at the center of the screen, in CSS pixels.
+	By *ScrollOffset `json:"by,omitempty"`
+	// To scrolls the content in the center of the screen to a position.
+	To *ScrollPosition `json:"to,omitempty"`
+}
+
+type ScrollOffset struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ScrollPosition is normalized from 0, the top or left end, to 1, the bottom or right end.
+type ScrollPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ScrollMaxOffset limits ScrollOffset in both directions, in CSS pixels.
+const ScrollMaxOffset = 100000
+
+type ZoomAction string
+
+const (
+	ZoomIn    ZoomAction = "in"
+	ZoomOut   ZoomAction = "out"
+	ZoomReset ZoomAction = "reset"
+	// ZoomSet zooms to ZoomRequest.Factor.
+	ZoomSet ZoomAction = "set"
+)
+
+func (ZoomAction) EnumValues() []string {
+	return []string{string(ZoomIn), string(ZoomOut), string(ZoomReset), string(ZoomSet)}
+}
+
+// Limits of ZoomRequest. Zooming in and out changes the factor by ZoomStep.
+const (
+	ZoomMinFactor = 1
+	ZoomMaxFactor = 5
+	ZoomStep      = 1.25
+)
+
+// ZoomRequest magnifies the shown content like pinching a touchpad. The zoom is reset when
+// other content is opened.
+type ZoomRequest struct {
+	Action ZoomAction `json:"action"`
+	// Factor is only used by ZoomSet.
+	Factor float64 `json:"factor,omitempty"`
+}
+
+type ZoomResponse struct {
+	Factor float64 `json:"factor"`
+}
+
+type MouseButton string
+
+const (
+	MouseButtonLeft   MouseButton = "left"
+	MouseButtonMiddle MouseButton = "middle"
+	MouseButtonRight  MouseButton = "right"
+)
+
+func (MouseButton) EnumValues() []string {
+	return []string{string(MouseButtonLeft), string(MouseButtonMiddle), string(MouseButtonRight)}
+}
+
+// ClickMaxCount limits ClickRequest.Count, e.g. 2 is a double click.
+const ClickMaxCount = 3
+
+// ClickRequest clicks at a position of the screen, normalized from 0, the top or left edge, to
+// 1, the bottom or right edge, so that it matches a screenshot of any size.
+type ClickRequest struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// Button defaults to MouseButtonLeft.
+	Button MouseButton `json:"button,omitempty"`
+	// Count defaults to 1.
+	Count int `json:"count,omitempty"`
+}
+
+type NavigateAction string
+
+const (
+	NavigateBack    NavigateAction = "back"
+	NavigateForward NavigateAction = "forward"
+	NavigateReload  NavigateAction = "reload"
+)
+
+func (NavigateAction) EnumValues() []string {
+	return []string{string(NavigateBack), string(NavigateForward), string(NavigateReload)}
+}
+
+// NavigateRequest moves through the history of the open website, or reloads it.
+type NavigateRequest struct {
+	Action NavigateAction `json:"action"`
+}
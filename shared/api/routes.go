@@ -0,0 +1,632 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const DisplayPort = 1323
+const ControlPort = 8080
+
+// Prefix is the group every route path below is relative to.
+const Prefix = "/api"
+
+// Display routes, in echo path syntax.
+const (
+	PathPing           = "/ping"
+	PathCapabilities   = "/capabilities"
+	PathShellCommand   = "/shellCommand"
+	PathKeyboardInput  = "/keyboardInput"
+	PathPointerInput   = "/pointerInput"
+	PathTypeText       = "/typeText"
+	PathMacro          = "/macro"
+	PathMacroName      = "/macro/:name"
+	PathShowHTML       = "/showHTML"
+	PathTakeScreenshot = "/takeScreenshot"
+	PathOpenWebsite    = "/openWebsite"
+	PathDisplayOptions = "/displayOptions"
+	PathOverlay        = "/overlay"
+	PathLayout         = "/layout"
+	PathLayoutZone     = "/layout/zone/:name"
+	PathTemplate       = "/template/:name"
+	PathTheme          = "/theme"
+	PathThemeName      = "/theme/:name"
+	PathIdleScreen     = "/idleScreen"
+	PathIdentify       = "/identify"
+	PathScroll         = "/scroll"
+	PathZoom           = "/zoom"
+	PathClick          = "/click"
+	PathNavigate       = "/navigate"
+	PathFile           = "/file/:path"
+	PathFilePreview    = "/file/preview/:path"
+	PathFileMeta       = "/file/meta/:path"
+	PathTranscode      = "/transcode"
+	PathTranscodeFile  = "/transcode/:path"
+	PathOpenAPI        = "/openapi.json"
+	PathUpdate         = "/update"
+	PathUpdateConfirm  = "/update/confirm"
+	PathUpdateRollback = "/update/rollback"
+)
+
+// Control routes, in echo path syntax.
+const (
+	PathHostPing      = "/ping"
+	PathWakeOnLan     = "/wakeOnLan"
+	PathStorage       = "/storage"
+	PathUpdateBinary  = "/update/binary"
+	PathUpdateRollout = "/update/rollout"
+	PathThemeApply    = "/theme/apply"
+)
+
+// WithPath fills the :path parameter of a route with a storage-relative file path.
+// Slashes are escaped so that the whole path stays a single route segment.
+func WithPath(route string, path string) string {
+	return strings.Replace(route, ":path", url.PathEscape(strings.TrimPrefix(path, "/")), 1)
+}
+
+// WithName fills the :name parameter of a route.
+func WithName(route string, name string) string {
+	return strings.Replace(route, ":name", url.PathEscape(name), 1)
+}
+
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	// Request and Response hold a value of the JSON body type, nil means no JSON body.
+	Request  any
+	Response any
+	// RequestContentType and ResponseContentType are set for raw, non JSON bodies.
+	RequestContentType  string
+	ResponseContentType string
+	// Query maps query parameter names to their description.
+	Query map[string]string
+	// Errors maps HTTP status codes to their meaning for this route.
+	Errors map[int]string
+}
+
+var DisplayRoutes = []Route{
+	{
+		Method:   http.MethodGet,
+		Path:     PathPing,
+		Summary:  "Check that the display app is running and get its version.",
+		Response: PingResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathCapabilities,
+		Summary:  "Get the API version, the supported features and the installed external tools of the display.",
+		Response: CapabilitiesResponse{},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathShellCommand,
+		Summary:  "Run a bash command inside the storage directory. Responds with 200 even when the command itself fails.",
+		Request:  ShellCommandRequest{},
+		Response: ShellCommandResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "The working directory does not exist (file_not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathKeyboardInput,
+		Summary:  "Press or release keys on the display.",
+		Request:  KeyboardInputRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "An action or key is unknown. No input was sent.",
+			http.StatusConflict:   "A macro or typed text is using the keyboard (busy).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathTypeText,
+		Summary:  "Type text with the keyboard layout of the display.",
+		Request:  TypeTextRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest:          "The text is too long, the delay is invalid, or the keyboard layout can not type a character. No input was sent.",
+			http.StatusConflict:            "A macro, keyboard input or other text is using the keyboard (busy).",
+			http.StatusInternalServerError: "The keyboard layout of the display is not supported.",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathMacro,
+		Summary:  "Get the stored keyboard macros.",
+		Response: MacrosResponse{},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathMacro,
+		Summary:  "Run the steps of a macro without storing it. The name is ignored. Responds once all steps ran.",
+		Request:  Macro{},
+		Response: EmptyResponse{},
+		Errors:   macroRunErrors,
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathMacroName,
+		Summary:  "Store a keyboard macro. The name of the route replaces the name in the body.",
+		Request:  Macro{},
+		Response: Macro{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The name or a step is invalid, e.g. a key is unknown.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathMacroName,
+		Summary:  "Run a stored macro. Responds once all steps ran.",
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "There is no macro with the name (not_found).",
+			http.StatusConflict: "Another macro is running (busy).",
+		},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     PathMacroName,
+		Summary:  "Remove a stored macro.",
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "There is no macro with the name (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathPointerInput,
+		Summary:  "Move the mouse, press, release or click its buttons and turn its wheel on the display.",
+		Request:  PointerInputRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "An action, position, button or scroll delta is invalid. No input was sent.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathShowHTML,
+		Summary:  "Show an HTML snippet full screen.",
+		Request:  ShowHTMLRequest{},
+		Response: EmptyResponse{},
+	},
+	{
+		Method:              http.MethodPatch,
+		Path:                PathTakeScreenshot,
+		Summary:             "Take a screenshot of the display.",
+		ResponseContentType: "image/png",
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathOpenWebsite,
+		Summary:  "Open a website full screen, optionally zoomed, reloaded, scrolled, with injected CSS and JS, cookies or credentials.",
+		Request:  OpenWebsiteRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The URL is no http or https URL, or an option is invalid.",
+			http.StatusForbidden:  "The URL is blocked by the allow or deny list of the display (website_blocked).",
+			http.StatusBadGateway: "The website could not be loaded or answered with an error status (navigation_failed).",
+		},
+	},
+	{
+		Method:             http.MethodPost,
+		Path:               PathFile,
+		Summary:            "Upload a file into the storage directory.",
+		RequestContentType: "application/octet-stream",
+		Response:           EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusConflict: "File with the same path and name already exists.",
+		},
+	},
+	{
+		Method:              http.MethodGet,
+		Path:                PathFile,
+		Summary:             "Download a file from the storage directory.",
+		ResponseContentType: "application/octet-stream",
+		Errors: map[int]string{
+			http.StatusNotFound: "Requested file was not found at the path.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathFile,
+		Summary:  "Open a file from the storage directory full screen.",
+		Response: EmptyResponse{},
+		Query: map[string]string{
+			QueryDisplayFit:                "Images and videos: contain, cover, fill or none. Overrides the display default.",
+			QueryDisplayRotation:           "Images and videos: clockwise rotation, 0, 90, 180 or 270. Overrides the display default.",
+			QueryDisplayBackground:         "Images and videos: CSS color around the media, or blur. Overrides the display default.",
+			QueryDisplayAlign:              "Images and videos: center, top, bottom, left, right, top-left, top-right, bottom-left or bottom-right. Overrides the display default.",
+			QueryDisplayTransition:         "cut, crossfade or fade through black from the shown content. Overrides the display default.",
+			QueryDisplayTransitionDuration: "Duration of the transition in milliseconds, up to 10000. Overrides the display default.",
+		},
+		Errors: map[int]string{
+			http.StatusBadRequest:           "A query parameter is invalid.",
+			http.StatusNotFound:             "Requested file was not found at the path.",
+			http.StatusUnsupportedMediaType: "The type of the file is not available for display.",
+		},
+	},
+	{
+		Method:              http.MethodGet,
+		Path:                PathFilePreview,
+		Summary:             "Get a thumbnail of a file from the storage directory, by default a 100px WebP square.",
+		ResponseContentType: "image/webp",
+		Query: map[string]string{
+			QueryPreviewSize:       "Width in pixels, 16 to 1920. Defaults to 100.",
+			QueryPreviewAspect:     "Ratio of width to height, e.g. 16:9. Defaults to 1:1.",
+			QueryPreviewFit:        "crop (default) fills the preview, contain keeps the whole file visible.",
+			QueryPreviewFormat:     "webp (default), jpeg or png. Also sets the response content type.",
+			QueryPreviewStoryboard: "Number of video frames, up to 50, placed next to each other. Each frame has the given size, all frames together may be at most 16383 pixels wide for webp and 65535 for jpeg.",
+		},
+		Errors: map[int]string{
+			http.StatusBadRequest:           "A query parameter is invalid.",
+			http.StatusNotFound:             "Requested file was not found at the path.",
+			http.StatusUnsupportedMediaType: "The type of the file is not available for preview generation.",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathDisplayOptions,
+		Summary:  "Get the defaults for showing content.",
+		Response: DisplayOptions{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathDisplayOptions,
+		Summary:  "Replace the defaults for showing content. They are stored on the display.",
+		Request:  DisplayOptions{},
+		Response: DisplayOptions{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "An option is invalid.",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathOverlay,
+		Summary:  "Get the overlays shown on top of all content.",
+		Response: Overlays{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathOverlay,
+		Summary:  "Replace the overlays shown on top of all content: clock, ticker, logo, lower third and countdown.",
+		Request:  Overlays{},
+		Response: Overlays{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "An overlay is invalid, or the logo is no image of at most 2 MiB.",
+			http.StatusNotFound:   "The logo was not found at the path.",
+		},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     PathOverlay,
+		Summary:  "Remove all overlays.",
+		Response: EmptyResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathLayout,
+		Summary:  "Get the stored layout. It has no zones if none is stored.",
+		Response: Layout{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathLayout,
+		Summary:  "Store a layout of zones with their own files, HTML, websites or playlists, and show it.",
+		Request:  Layout{},
+		Response: Layout{},
+		Errors:   layoutErrors,
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathLayout,
+		Summary:  "Show the stored layout again, e.g. after a file was opened.",
+		Response: EmptyResponse{},
+		Errors:   layoutErrors,
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     PathLayout,
+		Summary:  "Remove the stored layout. The screen does not change.",
+		Response: EmptyResponse{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathLayoutZone,
+		Summary:  "Replace the items of a zone of the stored layout. A shown layout only changes that zone.",
+		Request:  ZoneRequest{},
+		Response: Zone{},
+		Errors:   layoutErrors,
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathTemplate,
+		Summary:  "Show a built-in template: title, bullets, countdown, stopwatch or clock.",
+		Request:  TemplateParameters{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "A parameter is invalid for the template.",
+			http.StatusNotFound:   "There is no template with the name (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathTheme,
+		Summary:  "Get the stored themes and the name of the active one.",
+		Response: ThemesResponse{},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     PathTheme,
+		Summary:  "Go back to the built-in look. The themes stay stored.",
+		Response: EmptyResponse{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathThemeName,
+		Summary:  "Store a theme. The name of the route replaces the name in the body.",
+		Request:  Theme{},
+		Response: Theme{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "A field of the theme is invalid, or the logo or background image is no image.",
+			http.StatusNotFound:   "The logo or background image was not found at the path (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathThemeName,
+		Summary:  "Activate a stored theme for text slides, templates and the start screen.",
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "There is no theme with the name (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     PathThemeName,
+		Summary:  "Remove a stored theme. Removing the active theme goes back to the built-in look.",
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "There is no theme with the name (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathIdleScreen,
+		Summary:  "Get what the display shows after starting.",
+		Response: IdleScreen{},
+	},
+	{
+		Method:   http.MethodPut,
+		Path:     PathIdleScreen,
+		Summary:  "Replace what the display shows after starting: the info panel, an image or HTML.",
+		Request:  IdleScreen{},
+		Response: IdleScreen{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "A field is invalid, or the image is no image.",
+			http.StatusNotFound:   "The image was not found at the path (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathIdleScreen,
+		Summary:  "Show the idle screen now.",
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "The image was not found at the path (not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathIdentify,
+		Summary:  "Show a large number, the name and the group on top of the content for a few seconds.",
+		Request:  IdentifyRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The duration is invalid.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathScroll,
+		Summary:  "Scroll the shown content by an offset, or to a normalized position.",
+		Request:  ScrollRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "Neither or both of by and to are set, or a value is out of range.",
+			http.StatusConflict:   "The content in the center of the screen can not be scrolled to a position (not_available).",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathZoom,
+		Summary:  "Zoom the shown content in or out. The zoom is reset when other content is opened.",
+		Request:  ZoomRequest{},
+		Response: ZoomResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The action or factor is invalid.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathClick,
+		Summary:  "Click at a normalized position of the screen.",
+		Request:  ClickRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The position, button or count is invalid.",
+		},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     PathNavigate,
+		Summary:  "Go back or forward in the history of the open website, or reload it.",
+		Request:  NavigateRequest{},
+		Response: EmptyResponse{},
+		Errors: map[int]string{
+			http.StatusBadRequest: "The action is invalid.",
+			http.StatusConflict:   "No website is open, or there is no page to go back or forward to (not_available).",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathFileMeta,
+		Summary:  "Get duration, resolution, codecs, orientation or page count of a file from the storage directory.",
+		Response: FileMetaResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "Requested file was not found at the path.",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathTranscode,
+		Summary:  "Get the state and progress of the conversions into display-safe formats.",
+		Response: TranscodeStatusResponse{},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathTranscodeFile,
+		Summary:  "Queue the conversion of a file from the storage directory into a display-safe format.",
+		Response: TranscodeJob{},
+		Errors: map[int]string{
+			http.StatusNotFound:             "Requested file was not found at the path.",
+			http.StatusConflict:             "The file is already queued, or the queue is full.",
+			http.StatusUnsupportedMediaType: "The file is neither a video nor an image.",
+		},
+	},
+	{
+		Method:             http.MethodPut,
+		Path:               PathUpdate,
+		Summary:            "Replace the display binary and restart. Needs the update token as bearer token and the " + HeaderUpdateSHA256 + " header.",
+		RequestContentType: "application/octet-stream",
+		Response:           EmptyResponse{},
+		Errors:             updateErrors,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathUpdateConfirm,
+		Summary:  "Mark the running binary as healthy. Unconfirmed updates are rolled back on the next start.",
+		Response: EmptyResponse{},
+		Errors:   updateErrors,
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathUpdateRollback,
+		Summary:  "Restore the binary from before the last update and restart.",
+		Response: EmptyResponse{},
+		Errors:   updateErrors,
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathOpenAPI,
+		Summary:  "This document.",
+		Response: new(any),
+	},
+}
+
+var updateErrors = map[int]string{
+	http.StatusUnauthorized:        "Missing or wrong update token (unauthorized).",
+	http.StatusForbidden:           "Updates are disabled on this display (unauthorized).",
+	http.StatusNotFound:            "There is no previous binary to roll back to (file_not_found).",
+	http.StatusConflict:            "Another update is in progress (busy).",
+	http.StatusUnprocessableEntity: "The checksum or signature does not match (checksum_mismatch, signature_invalid).",
+}
+
+var macroRunErrors = map[int]string{
+	http.StatusBadRequest: "A step is invalid, e.g. a key is unknown.",
+	http.StatusConflict:   "Another macro, keyboard input or typed text is using the keyboard (busy).",
+}
+
+var layoutErrors = map[int]string{
+	http.StatusBadRequest:           "The layout is invalid.",
+	http.StatusForbidden:            "A URL is blocked by the allow or deny list of the display (website_blocked).",
+	http.StatusNotFound:             "No layout is stored or there is no zone with the name (not_found), or a file of an item was not found (file_not_found).",
+	http.StatusUnsupportedMediaType: "A file can not be shown in a zone.",
+}
+
+var ControlRoutes = []Route{
+	{
+		Method:   http.MethodGet,
+		Path:     PathHostPing,
+		Summary:  "Check whether a display host and its app are reachable.",
+		Query:    map[string]string{"ip": "Address of the display."},
+		Response: HostPingResponse{},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathWakeOnLan,
+		Summary:  "Send a Wake-on-LAN packet.",
+		Request:  WakeOnLanRequest{},
+		Response: EmptyResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathStorage,
+		Summary:  "Get the persisted frontend state.",
+		Response: new(any),
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathStorage,
+		Summary:  "Replace the persisted frontend state.",
+		Request:  new(any),
+		Response: EmptyResponse{},
+	},
+	{
+		Method:             http.MethodPut,
+		Path:               PathUpdateBinary,
+		Summary:            "Store a display binary for rollouts. Needs the update token as bearer token, the " + HeaderUpdateSHA256 + " and " + HeaderUpdateVersion + " headers, and the " + HeaderUpdateSignature + " header unless unsigned updates are allowed.",
+		RequestContentType: "application/octet-stream",
+		Response:           UpdateBinaryResponse{},
+		Errors: map[int]string{
+			http.StatusUnauthorized:        "The update token is missing or wrong (unauthorized).",
+			http.StatusForbidden:           "No update token is configured (unauthorized).",
+			http.StatusConflict:            "A rollout is running (busy).",
+			http.StatusUnprocessableEntity: "The checksum or the signature does not match, the signature is missing or no public key is configured to check it (checksum_mismatch, signature_invalid).",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathUpdateBinary,
+		Summary:  "Get information about the stored display binary.",
+		Response: UpdateBinaryResponse{},
+		Errors: map[int]string{
+			http.StatusNotFound: "No binary was uploaded yet (file_not_found).",
+		},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathUpdateRollout,
+		Summary:  "Start updating the displays to the stored binary, one group at a time. Needs the update token as bearer token.",
+		Request:  RolloutRequest{},
+		Response: RolloutStatusResponse{},
+		Errors: map[int]string{
+			http.StatusUnauthorized:        "The update token is missing or wrong (unauthorized).",
+			http.StatusForbidden:           "No update token is configured (unauthorized).",
+			http.StatusNotFound:            "No binary was uploaded yet (file_not_found).",
+			http.StatusConflict:            "A rollout is already running (busy).",
+			http.StatusUnprocessableEntity: "The stored binary does not match its checksum or signature, or it is unsigned and unsigned updates are not allowed (signature_invalid).",
+		},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathUpdateRollout,
+		Summary:  "Get the progress of the current or last rollout.",
+		Response: RolloutStatusResponse{},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     PathThemeApply,
+		Summary:  "Store a theme on displays and activate it there. Logo and background image have to be uploaded to the displays first. Each display gets its own result.",
+		Request:  ThemeApplyRequest{},
+		Response: ThemeApplyResponse{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     PathOpenAPI,
+		Summary:  "This document.",
+		Response: new(any),
+	},
+}
@@ -0,0 +1,57 @@
+package api
+
+// TemplateName is a built-in template of the display, shown with PathTemplate.
+type TemplateName string
+
+const (
+	// TemplateTitle is a slide with a title and an optional subtitle.
+	TemplateTitle TemplateName = "title"
+	// TemplateBullets is a slide with an optional title and a list, the text shrinks until it fits.
+	TemplateBullets TemplateName = "bullets"
+	// TemplateCountdown counts down to a time or for a duration.
+	TemplateCountdown TemplateName = "countdown"
+	// TemplateStopwatch counts up from 0:00. Space pauses and resumes it, Backspace resets it.
+	TemplateStopwatch TemplateName = "stopwatch"
+	TemplateClock     TemplateName = "clock"
+)
+
+func (TemplateName) EnumValues() []string {
+	return []string{string(TemplateTitle), string(TemplateBullets), string(TemplateCountdown), string(TemplateStopwatch), string(TemplateClock)}
+}
+
+// ClockStyle is the style of TemplateClock.
+type ClockStyle string
+
+const (
+	ClockDigital ClockStyle = "digital"
+	ClockAnalog  ClockStyle = "analog"
+)
+
+func (ClockStyle) EnumValues() []string {
+	return []string{string(ClockDigital), string(ClockAnalog)}
+}
+
+// TemplateMaxBullets limits the items of TemplateBullets.
+const TemplateMaxBullets = 50
+
+// TemplateParameters are the parameters of all templates, each template only reads its own.
+type TemplateParameters struct {
+	// Title is required for TemplateTitle and optional for TemplateBullets.
+	Title    string `json:"title,omitempty"`
+	Subtitle string `json:"subtitle,omitempty"`
+	// Items are the bullets of TemplateBullets.
+	Items []string `json:"items,omitempty"`
+	// Until is the end of TemplateCountdown in RFC 3339, e.g. "2026-05-04T18:00:00+02:00".
+	Until string `json:"until,omitempty"`
+	// Duration of TemplateCountdown in seconds, starting when it is shown. Either Until or
+	// Duration is set.
+	Duration int `json:"duration,omitempty"`
+	// Label is shown above the time of TemplateCountdown, TemplateStopwatch and TemplateClock.
+	Label string `json:"label,omitempty"`
+	// FinishedText replaces the time when TemplateCountdown is over.
+	FinishedText string `json:"finishedText,omitempty"`
+	// Style of TemplateClock, defaults to ClockDigital.
+	Style ClockStyle `json:"style,omitempty"`
+	// Seconds shows the seconds of TemplateClock.
+	Seconds bool `json:"seconds,omitempty"`
+}
@@ -0,0 +1,48 @@
+package api
+
+// ThemeMaxCSSSize is the size of Theme.CSS in bytes.
+const ThemeMaxCSSSize = 64 << 10
+
+// Theme changes the look of text slides, templates and the start screen of a display.
+// Every field is optional, unset fields keep the built-in look.
+type Theme struct {
+	// Name addresses the theme in PathThemeName, it may contain letters, digits, - and _.
+	Name            string `json:"name"`
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+	ForegroundColor string `json:"foregroundColor,omitempty"`
+	// AccentColor is used for headings.
+	AccentColor string `json:"accentColor,omitempty"`
+	// FontFamily is a CSS font family list, e.g. "Inter, sans-serif".
+	FontFamily string `json:"fontFamily,omitempty"`
+	// FontSize is the CSS length of normal text, e.g. "4rem" or "5vh".
+	FontSize string `json:"fontSize,omitempty"`
+	// Logo is the storage-relative path of an image shown in the top left corner.
+	Logo string `json:"logo,omitempty"`
+	// BackgroundImage is the storage-relative path of an image that covers the screen.
+	BackgroundImage string `json:"backgroundImage,omitempty"`
+	// CSS is added after all other styles.
+	CSS string `json:"css,omitempty"`
+}
+
+type ThemesResponse struct {
+	// Active is the name of the active theme, empty for the built-in look.
+	Active string  `json:"active"`
+	Themes []Theme `json:"themes"`
+}
+
+// ThemeApplyRequest stores a theme on displays and activates it there. Logo and
+// BackgroundImage are not copied, they have to be on the displays already.
+type ThemeApplyRequest struct {
+	Theme Theme `json:"theme"`
+	// IPs are the displays, e.g. all displays of a group.
+	IPs []string `json:"ips"`
+}
+
+type ThemeApplyResult struct {
+	IP    string `json:"ip"`
+	Error string `json:"error,omitempty"`
+}
+
+type ThemeApplyResponse struct {
+	Results []ThemeApplyResult `json:"results"`
+}
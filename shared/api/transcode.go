@@ -0,0 +1,39 @@
+package api
+
+type TranscodeState string
+
+const (
+	TranscodeQueued  TranscodeState = "queued"
+	TranscodeRunning TranscodeState = "running"
+	TranscodeDone    TranscodeState = "done"
+	TranscodeFailed  TranscodeState = "failed"
+	// TranscodeSkipped means the file can already be shown as it is.
+	TranscodeSkipped TranscodeState = "skipped"
+)
+
+func (TranscodeState) EnumValues() []string {
+	return []string{
+		string(TranscodeQueued),
+		string(TranscodeRunning),
+		string(TranscodeDone),
+		string(TranscodeFailed),
+		string(TranscodeSkipped),
+	}
+}
+
+// TranscodeJob is the conversion of one file into a format the display can show.
+type TranscodeJob struct {
+	// Path is the storage-relative path of the original file.
+	Path string `json:"path"`
+	// Output is the storage-relative path of the converted file. It replaces the original once
+	// it is verified, so both paths are the same if only the codec changed.
+	Output   string         `json:"output,omitempty"`
+	State    TranscodeState `json:"state"`
+	Progress float64        `json:"progress"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type TranscodeStatusResponse struct {
+	// Jobs holds the running and queued jobs and the most recent finished ones, oldest first.
+	Jobs []TranscodeJob `json:"jobs"`
+}
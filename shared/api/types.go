@@ -0,0 +1,106 @@
+package api
+
+import "plg-mudics/shared"
+
+type ErrorResponse = shared.ErrorResponse
+
+type ErrorCode = shared.ErrorCode
+
+type ShellCommandResponse = shared.CommandResponse
+
+// EmptyResponse is returned by every route that has nothing else to report.
+type EmptyResponse struct{}
+
+type PingResponse struct {
+	Version string `json:"version"`
+}
+
+type ShellCommandRequest struct {
+	Command string `json:"command"`
+	// Dir is the storage-relative working directory, defaults to the storage root.
+	Dir string `json:"dir,omitempty"`
+}
+
+type KeyAction string
+
+const (
+	KeyActionPress   KeyAction = "press"
+	KeyActionRelease KeyAction = "release"
+)
+
+func (KeyAction) EnumValues() []string {
+	return []string{string(KeyActionPress), string(KeyActionRelease)}
+}
+
+type KeyboardInput struct {
+	Key    string    `json:"key"`
+	Action KeyAction `json:"action"`
+}
+
+type KeyboardInputRequest struct {
+	Inputs []KeyboardInput `json:"inputs"`
+}
+
+// Limits of TypeTextRequest, the delay is in milliseconds.
+const (
+	TypeTextMaxLength    = 10000
+	TypeTextDefaultDelay = 10
+	TypeTextMaxDelay     = 1000
+)
+
+// TypeTextRequest types text with the keyboard layout of the display.
+type TypeTextRequest struct {
+	Text string `json:"text"`
+	// Delay is the pause between two characters in milliseconds, defaults to
+	// TypeTextDefaultDelay if nil.
+	Delay *int `json:"delay,omitempty"`
+}
+
+type ShowHTMLRequest struct {
+	HTML string `json:"html"`
+}
+
+type HostStatus string
+
+const (
+	HostOffline HostStatus = "host_offline"
+	AppOffline  HostStatus = "app_offline"
+	AppOnline   HostStatus = "app_online"
+)
+
+func (HostStatus) EnumValues() []string {
+	return []string{string(HostOffline), string(AppOffline), string(AppOnline)}
+}
+
+type HostPingResponse struct {
+	Status HostStatus `json:"status"`
+	// Capabilities and Compatibility are only set when the status is app_online.
+	Capabilities  *CapabilitiesResponse `json:"capabilities,omitempty"`
+	Compatibility Compatibility         `json:"compatibility,omitempty"`
+}
+
+type WakeOnLanRequest struct {
+	MACAddress string `json:"mac_address"`
+}
+
+// FileMetaResponse describes a media file. Fields that do not apply to the file type, or
+// that could not be read because a tool is missing, are left out.
+type FileMetaResponse struct {
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+	// Duration of videos and audio files in seconds.
+	Duration float64 `json:"duration,omitempty"`
+	// Width and Height of videos and images in pixels, before applying the orientation.
+	Width        int     `json:"width,omitempty"`
+	Height       int     `json:"height,omitempty"`
+	VideoCodec   string  `json:"videoCodec,omitempty"`
+	VideoProfile string  `json:"videoProfile,omitempty"`
+	FrameRate    float64 `json:"frameRate,omitempty"`
+	// HasAudio is only set once ffprobe read the streams, so false means there is no audio.
+	HasAudio   *bool  `json:"hasAudio,omitempty"`
+	AudioCodec string `json:"audioCodec,omitempty"`
+	// Orientation is the EXIF orientation of images, 1 (upright) to 8.
+	Orientation int `json:"orientation,omitempty"`
+	// Pages of PDFs or slides of presentations.
+	Pages int `json:"pages,omitempty"`
+}
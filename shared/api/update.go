@@ -0,0 +1,58 @@
+package api
+
+// Headers sent along with a display binary, both to the control server and to a display.
+const (
+	// HeaderUpdateSHA256 is the hex encoded SHA-256 checksum of the binary.
+	HeaderUpdateSHA256 = "X-Update-Sha256"
+	// HeaderUpdateSignature is the base64 encoded ed25519 signature of the raw checksum bytes.
+	HeaderUpdateSignature = "X-Update-Signature"
+	// HeaderUpdateVersion is the version of the binary, used for the health check after the update.
+	HeaderUpdateVersion = "X-Update-Version"
+)
+
+type UpdateBinaryResponse struct {
+	Version   string `json:"version"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+	Size      int64  `json:"size"`
+}
+
+type RolloutRequest struct {
+	// Groups are updated one after another, the displays of a group at the same time.
+	Groups [][]string `json:"groups"`
+}
+
+type RolloutState string
+
+const (
+	RolloutPending    RolloutState = "pending"
+	RolloutUpdating   RolloutState = "updating"
+	RolloutUpdated    RolloutState = "updated"
+	RolloutFailed     RolloutState = "failed"
+	RolloutRolledBack RolloutState = "rolled_back"
+	RolloutSkipped    RolloutState = "skipped"
+)
+
+func (RolloutState) EnumValues() []string {
+	return []string{
+		string(RolloutPending),
+		string(RolloutUpdating),
+		string(RolloutUpdated),
+		string(RolloutFailed),
+		string(RolloutRolledBack),
+		string(RolloutSkipped),
+	}
+}
+
+type RolloutDisplayStatus struct {
+	IP    string       `json:"ip"`
+	Group int          `json:"group"`
+	State RolloutState `json:"state"`
+	Error string       `json:"error,omitempty"`
+}
+
+type RolloutStatusResponse struct {
+	Running  bool                   `json:"running"`
+	Version  string                 `json:"version"`
+	Displays []RolloutDisplayStatus `json:"displays"`
+}
@@ -0,0 +1,43 @@
+package api
+
+// Limits of OpenWebsiteRequest.
+const (
+	WebsiteMinZoom = 0.25
+	WebsiteMaxZoom = 5
+	// WebsiteMinReloadInterval is in seconds.
+	WebsiteMinReloadInterval = 10
+	WebsiteMaxScrollSpeed    = 1000
+	// WebsiteMaxInjectedSize is the size of CSS and JS together in bytes.
+	WebsiteMaxInjectedSize = 256 << 10
+)
+
+type OpenWebsiteRequest struct {
+	URL string `json:"url"`
+	// Zoom scales the page, defaults to 1.
+	Zoom float64 `json:"zoom,omitempty"`
+	// ReloadInterval reloads the page every n seconds, also after a failed load. 0 never reloads.
+	ReloadInterval int `json:"reloadInterval,omitempty"`
+	// ScrollSpeed scrolls down in pixels per second and starts at the top again at the end.
+	ScrollSpeed int `json:"scrollSpeed,omitempty"`
+	// CSS is added to every page and frame of the website, e.g. to hide cookie banners.
+	CSS string `json:"css,omitempty"`
+	// JS runs in every page and frame of the website before its own scripts.
+	JS string `json:"js,omitempty"`
+	// Cookies are set before the website is opened. They stay in the browser profile.
+	Cookies   []WebsiteCookie   `json:"cookies,omitempty"`
+	BasicAuth *WebsiteBasicAuth `json:"basicAuth,omitempty"`
+}
+
+type WebsiteCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Domain defaults to the host of the URL.
+	Domain string `json:"domain,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// WebsiteBasicAuth answers HTTP basic and digest authentication of the website.
+type WebsiteBasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,439 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"plg-mudics/shared"
+	"plg-mudics/shared/api"
+)
+
+// Display talks to the API of a single display.
+type Display struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// UpdateToken is sent as bearer token, it is only needed for the update routes.
+	UpdateToken string
+}
+
+// Error is returned when the display answers with a non 2xx status.
+type Error struct {
+	StatusCode int
+	Response   api.ErrorResponse
+}
+
+// IsCode reports whether err is an Error with the given code.
+func IsCode(err error, code shared.ErrorCode) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Response.Code == code
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("display responded with %d (%s): %s", e.StatusCode, e.Response.Code, e.Response.Description)
+}
+
+// NewDisplay creates a client for the display at the given host (ip or hostname) on the default port.
+func NewDisplay(host string) *Display {
+	return &Display{
+		BaseURL:    "http://" + net.JoinHostPort(host, strconv.Itoa(api.DisplayPort)),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (d *Display) Ping() (api.PingResponse, error) {
+	var response api.PingResponse
+	err := d.doJSON(http.MethodGet, api.PathPing, nil, &response)
+	return response, err
+}
+
+// Capabilities falls back to the legacy feature set for displays that predate the capabilities endpoint.
+func (d *Display) Capabilities() (api.CapabilitiesResponse, error) {
+	var response api.CapabilitiesResponse
+	err := d.doJSON(http.MethodGet, api.PathCapabilities, nil, &response)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return response, err
+	}
+
+	ping, err := d.Ping()
+	if err != nil {
+		return response, err
+	}
+	return api.CapabilitiesResponse{Version: ping.Version, APIVersion: 1, Features: api.LegacyFeatures}, nil
+}
+
+// ShellCommand runs command in dir, which is relative to the storage directory.
+func (d *Display) ShellCommand(command string, dir string) (api.ShellCommandResponse, error) {
+	var response api.ShellCommandResponse
+	err := d.doJSON(http.MethodPatch, api.PathShellCommand, api.ShellCommandRequest{Command: command, Dir: dir}, &response)
+	return response, err
+}
+
+func (d *Display) KeyboardInput(inputs []api.KeyboardInput) error {
+	return d.doJSON(http.MethodPatch, api.PathKeyboardInput, api.KeyboardInputRequest{Inputs: inputs}, nil)
+}
+
+// TypeText types text with the keyboard layout of the display, delay nil for the default.
+func (d *Display) TypeText(text string, delay *int) error {
+	return d.doJSON(http.MethodPatch, api.PathTypeText, api.TypeTextRequest{Text: text, Delay: delay}, nil)
+}
+
+// Macros returns the keyboard macros stored on the display.
+func (d *Display) Macros() (api.MacrosResponse, error) {
+	var response api.MacrosResponse
+	err := d.doJSON(http.MethodGet, api.PathMacro, nil, &response)
+	return response, err
+}
+
+// SetMacro stores a keyboard macro under its name.
+func (d *Display) SetMacro(macro api.Macro) (api.Macro, error) {
+	var response api.Macro
+	err := d.doJSON(http.MethodPut, api.WithName(api.PathMacroName, macro.Name), macro, &response)
+	return response, err
+}
+
+// RunMacro runs a stored keyboard macro and returns once it finished.
+func (d *Display) RunMacro(name string) error {
+	return d.doJSON(http.MethodPatch, api.WithName(api.PathMacroName, name), nil, nil)
+}
+
+// RunMacroSteps runs keyboard macro steps without storing them.
+func (d *Display) RunMacroSteps(steps []api.MacroStep) error {
+	return d.doJSON(http.MethodPatch, api.PathMacro, api.Macro{Steps: steps}, nil)
+}
+
+// DeleteMacro removes a stored keyboard macro.
+func (d *Display) DeleteMacro(name string) error {
+	return d.doJSON(http.MethodDelete, api.WithName(api.PathMacroName, name), nil, nil)
+}
+
+// PointerInput moves the mouse, clicks and scrolls on the display.
+func (d *Display) PointerInput(inputs []api.PointerInput) error {
+	return d.doJSON(http.MethodPatch, api.PathPointerInput, api.PointerInputRequest{Inputs: inputs}, nil)
+}
+
+func (d *Display) ShowHTML(html string) error {
+	return d.doJSON(http.MethodPatch, api.PathShowHTML, api.ShowHTMLRequest{HTML: html}, nil)
+}
+
+func (d *Display) OpenWebsite(url string) error {
+	return d.doJSON(http.MethodPatch, api.PathOpenWebsite, api.OpenWebsiteRequest{URL: url}, nil)
+}
+
+// OpenWebsiteWithOptions opens a website with the kiosk options of the request.
+func (d *Display) OpenWebsiteWithOptions(request api.OpenWebsiteRequest) error {
+	return d.doJSON(http.MethodPatch, api.PathOpenWebsite, request, nil)
+}
+
+// TakeScreenshot returns the screenshot as PNG.
+func (d *Display) TakeScreenshot() ([]byte, error) {
+	return d.doBytes(http.MethodPatch, api.PathTakeScreenshot, nil, nil)
+}
+
+func (d *Display) UploadFile(path string, content io.Reader) error {
+	response, err := d.do(http.MethodPost, api.WithPath(api.PathFile, path), content, octetStreamHeader())
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// DownloadFile returns the file content, the caller has to close it.
+func (d *Display) DownloadFile(path string) (io.ReadCloser, error) {
+	response, err := d.do(http.MethodGet, api.WithPath(api.PathFile, path), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// OpenFile shows a file full screen. The zero options use the defaults of the display.
+func (d *Display) OpenFile(path string, options api.DisplayOptions) error {
+	route := api.WithPath(api.PathFile, path)
+	if query := options.Query().Encode(); query != "" {
+		route += "?" + query
+	}
+	return d.doJSON(http.MethodPatch, route, nil, nil)
+}
+
+// DisplayOptions returns the defaults for showing content.
+func (d *Display) DisplayOptions() (api.DisplayOptions, error) {
+	var response api.DisplayOptions
+	err := d.doJSON(http.MethodGet, api.PathDisplayOptions, nil, &response)
+	return response, err
+}
+
+// SetDisplayOptions replaces the defaults for showing content.
+func (d *Display) SetDisplayOptions(options api.DisplayOptions) (api.DisplayOptions, error) {
+	var response api.DisplayOptions
+	err := d.doJSON(http.MethodPut, api.PathDisplayOptions, options, &response)
+	return response, err
+}
+
+// FilePreview returns the thumbnail of a file. The zero options return a 100px WebP square.
+func (d *Display) FilePreview(path string, options api.PreviewOptions) ([]byte, error) {
+	route := api.WithPath(api.PathFilePreview, path)
+	if query := options.Query().Encode(); query != "" {
+		route += "?" + query
+	}
+	return d.doBytes(http.MethodGet, route, nil, nil)
+}
+
+// Overlays returns the overlays shown on top of all content.
+func (d *Display) Overlays() (api.Overlays, error) {
+	var response api.Overlays
+	err := d.doJSON(http.MethodGet, api.PathOverlay, nil, &response)
+	return response, err
+}
+
+// SetOverlays replaces the overlays shown on top of all content.
+func (d *Display) SetOverlays(overlays api.Overlays) (api.Overlays, error) {
+	var response api.Overlays
+	err := d.doJSON(http.MethodPut, api.PathOverlay, overlays, &response)
+	return response, err
+}
+
+// ClearOverlays removes all overlays.
+func (d *Display) ClearOverlays() error {
+	return d.doJSON(http.MethodDelete, api.PathOverlay, nil, nil)
+}
+
+// Layout returns the stored layout.
+func (d *Display) Layout() (api.Layout, error) {
+	var response api.Layout
+	err := d.doJSON(http.MethodGet, api.PathLayout, nil, &response)
+	return response, err
+}
+
+// SetLayout stores a layout and shows it.
+func (d *Display) SetLayout(layout api.Layout) (api.Layout, error) {
+	var response api.Layout
+	err := d.doJSON(http.MethodPut, api.PathLayout, layout, &response)
+	return response, err
+}
+
+// ShowLayout shows the stored layout again.
+func (d *Display) ShowLayout() error {
+	return d.doJSON(http.MethodPatch, api.PathLayout, nil, nil)
+}
+
+// DeleteLayout removes the stored layout.
+func (d *Display) DeleteLayout() error {
+	return d.doJSON(http.MethodDelete, api.PathLayout, nil, nil)
+}
+
+// SetZone replaces the items of a zone of the stored layout.
+func (d *Display) SetZone(name string, zone api.ZoneRequest) (api.Zone, error) {
+	var response api.Zone
+	err := d.doJSON(http.MethodPut, api.WithName(api.PathLayoutZone, name), zone, &response)
+	return response, err
+}
+
+// ShowTemplate shows a built-in template with its parameters.
+func (d *Display) ShowTemplate(name api.TemplateName, parameters api.TemplateParameters) error {
+	return d.doJSON(http.MethodPatch, api.WithName(api.PathTemplate, string(name)), parameters, nil)
+}
+
+// Themes returns the stored themes and the name of the active one.
+func (d *Display) Themes() (api.ThemesResponse, error) {
+	var response api.ThemesResponse
+	err := d.doJSON(http.MethodGet, api.PathTheme, nil, &response)
+	return response, err
+}
+
+// SetTheme stores a theme under its name.
+func (d *Display) SetTheme(theme api.Theme) (api.Theme, error) {
+	var response api.Theme
+	err := d.doJSON(http.MethodPut, api.WithName(api.PathThemeName, theme.Name), theme, &response)
+	return response, err
+}
+
+// ActivateTheme activates a stored theme.
+func (d *Display) ActivateTheme(name string) error {
+	return d.doJSON(http.MethodPatch, api.WithName(api.PathThemeName, name), nil, nil)
+}
+
+// DeleteTheme removes a stored theme.
+func (d *Display) DeleteTheme(name string) error {
+	return d.doJSON(http.MethodDelete, api.WithName(api.PathThemeName, name), nil, nil)
+}
+
+// ResetTheme goes back to the built-in look.
+func (d *Display) ResetTheme() error {
+	return d.doJSON(http.MethodDelete, api.PathTheme, nil, nil)
+}
+
+// IdleScreen returns what the display shows after starting.
+func (d *Display) IdleScreen() (api.IdleScreen, error) {
+	var response api.IdleScreen
+	err := d.doJSON(http.MethodGet, api.PathIdleScreen, nil, &response)
+	return response, err
+}
+
+// SetIdleScreen replaces what the display shows after starting.
+func (d *Display) SetIdleScreen(idleScreen api.IdleScreen) (api.IdleScreen, error) {
+	var response api.IdleScreen
+	err := d.doJSON(http.MethodPut, api.PathIdleScreen, idleScreen, &response)
+	return response, err
+}
+
+// ShowIdleScreen shows the idle screen now.
+func (d *Display) ShowIdleScreen() error {
+	return d.doJSON(http.MethodPatch, api.PathIdleScreen, nil, nil)
+}
+
+// Identify shows the number, name and group of the display on top of its content.
+func (d *Display) Identify(request api.IdentifyRequest) error {
+	return d.doJSON(http.MethodPatch, api.PathIdentify, request, nil)
+}
+
+// Scroll scrolls the shown content by an offset or to a position.
+func (d *Display) Scroll(request api.ScrollRequest) error {
+	return d.doJSON(http.MethodPatch, api.PathScroll, request, nil)
+}
+
+// Zoom zooms the shown content and returns the new zoom factor.
+func (d *Display) Zoom(request api.ZoomRequest) (api.ZoomResponse, error) {
+	var response api.ZoomResponse
+	err := d.doJSON(http.MethodPatch, api.PathZoom, request, &response)
+	return response, err
+}
+
+// Click clicks at a normalized position of the screen.
+func (d *Display) Click(request api.ClickRequest) error {
+	return d.doJSON(http.MethodPatch, api.PathClick, request, nil)
+}
+
+// Navigate goes back or forward in the history of the open website, or reloads it.
+func (d *Display) Navigate(action api.NavigateAction) error {
+	return d.doJSON(http.MethodPatch, api.PathNavigate, api.NavigateRequest{Action: action}, nil)
+}
+
+// FileMeta returns the media metadata of a file.
+func (d *Display) FileMeta(path string) (api.FileMetaResponse, error) {
+	var response api.FileMetaResponse
+	err := d.doJSON(http.MethodGet, api.WithPath(api.PathFileMeta, path), nil, &response)
+	return response, err
+}
+
+// Transcode queues the conversion of a file into a format the display can show.
+func (d *Display) Transcode(path string) (api.TranscodeJob, error) {
+	var response api.TranscodeJob
+	err := d.doJSON(http.MethodPost, api.WithPath(api.PathTranscodeFile, path), nil, &response)
+	return response, err
+}
+
+// TranscodeStatus returns the state of the recent conversions.
+func (d *Display) TranscodeStatus() (api.TranscodeStatusResponse, error) {
+	var response api.TranscodeStatusResponse
+	err := d.doJSON(http.MethodGet, api.PathTranscode, nil, &response)
+	return response, err
+}
+
+// Update sends a new binary to the display, which restarts with it afterwards.
+// The signature is only checked by displays with a configured public key and may be empty.
+func (d *Display) Update(binary io.Reader, checksum string, signature string) error {
+	header := octetStreamHeader()
+	header.Set(api.HeaderUpdateSHA256, checksum)
+	if signature != "" {
+		header.Set(api.HeaderUpdateSignature, signature)
+	}
+
+	response, err := d.do(http.MethodPut, api.PathUpdate, binary, header)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+func (d *Display) ConfirmUpdate() error {
+	return d.doJSON(http.MethodPost, api.PathUpdateConfirm, nil, nil)
+}
+
+func (d *Display) RollbackUpdate() error {
+	return d.doJSON(http.MethodPost, api.PathUpdateRollback, nil, nil)
+}
+
+func (d *Display) doJSON(method string, route string, request any, response any) error {
+	var body io.Reader
+	header := http.Header{}
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := d.do(method, route, body, header)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (d *Display) doBytes(method string, route string, body io.Reader, header http.Header) ([]byte, error) {
+	response, err := d.do(method, route, body, header)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+func (d *Display) do(method string, route string, body io.Reader, header http.Header) (*http.Response, error) {
+	request, err := http.NewRequest(method, d.BaseURL+api.Prefix+route, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	if d.UpdateToken != "" {
+		request.Header.Set("Authorization", "Bearer "+d.UpdateToken)
+	}
+
+	response, err := d.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		defer response.Body.Close()
+		apiErr := &Error{StatusCode: response.StatusCode}
+		if err := json.NewDecoder(response.Body).Decode(&apiErr.Response); err != nil {
+			apiErr.Response.Code = shared.CodeInternal
+			apiErr.Response.Description = http.StatusText(response.StatusCode)
+		}
+		return nil, apiErr
+	}
+
+	return response, nil
+}
+
+func octetStreamHeader() http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	return header
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Load fills cfg, a pointer to a struct already holding the defaults, from a JSON file,
+// environment variables and command line flags. Later sources override earlier ones.
+//
+// Fields are configured with struct tags: `json` for the file, `env` for the environment
+// variable, `flag` for the command line flag and `usage` for its help text. Supported field
+// types are string, bool, int and []string (comma separated in env and flags).
+//
+// The file is read from the --config flag, falling back to ~/.config/plg-mudics/<name>.json.
+// A missing file is not an error.
+func Load(cfg any, name string, args []string) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return errors.New("config has to be a pointer to a struct")
+	}
+	value = value.Elem()
+
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := flagSet.String("config", defaultConfigPath(name), "path to the JSON config file")
+
+	var pending []*pendingFlag
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		flagName := field.Tag.Get("flag")
+		if flagName == "" {
+			continue
+		}
+		p := &pendingFlag{field: value.Field(i), defaultValue: format(value.Field(i))}
+		flagSet.Var(p, flagName, field.Tag.Get("usage"))
+		pending = append(pending, p)
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", *configPath, err)
+		}
+	}
+
+	for i := range value.NumField() {
+		env := value.Type().Field(i).Tag.Get("env")
+		if raw, ok := os.LookupEnv(env); ok && env != "" {
+			if err := parse(value.Field(i), raw); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", env, err)
+			}
+		}
+	}
+
+	for _, p := range pending {
+		if p.value == nil {
+			continue
+		}
+		if err := parse(p.field, *p.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetLogLevel sets the level of the default logger, e.g. "debug" or "warn".
+func SetLogLevel(level string) error {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	slog.SetLogLoggerLevel(parsed)
+	return nil
+}
+
+func defaultConfigPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "plg-mudics", name+".json")
+}
+
+// pendingFlag remembers the value of a flag until the file and environment are applied.
+type pendingFlag struct {
+	field        reflect.Value
+	defaultValue string
+	value        *string
+}
+
+func (p *pendingFlag) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.defaultValue
+}
+
+func (p *pendingFlag) Set(raw string) error {
+	// validate early, so that flag can print the usage
+	if err := parse(reflect.New(p.field.Type()).Elem(), raw); err != nil {
+		return err
+	}
+	p.value = &raw
+	return nil
+}
+
+func (p *pendingFlag) IsBoolFlag() bool {
+	return p.field.Kind() == reflect.Bool
+}
+
+func parse(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported config field type %s", field.Type())
+		}
+		parts := []string{}
+		for part := range strings.SplitSeq(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		field.Set(reflect.ValueOf(parts))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+func format(field reflect.Value) string {
+	if field.Kind() == reflect.Slice {
+		return strings.Join(field.Interface().([]string), ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
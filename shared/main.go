@@ -15,7 +15,53 @@ type CommandResponse struct {
 }
 
 type ErrorResponse struct {
-	Description string `json:"description"`
+	Code        ErrorCode         `json:"code"`
+	Description string            `json:"description"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// ErrorCode is a stable, machine-readable identifier for an error. Other than
+// the description, clients are allowed to depend on it.
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "bad_request"
+	CodeInternal             ErrorCode = "internal_error"
+	CodePathInvalid          ErrorCode = "path_invalid"
+	CodeFileNotFound         ErrorCode = "file_not_found"
+	CodeNotFound             ErrorCode = "not_found"
+	CodeAlreadyExists        ErrorCode = "already_exists"
+	CodeUnsupportedMediaType ErrorCode = "unsupported_media_type"
+	CodePreviewToolsMissing  ErrorCode = "preview_tools_missing"
+	CodeUnauthorized         ErrorCode = "unauthorized"
+	CodeChecksumMismatch     ErrorCode = "checksum_mismatch"
+	CodeSignatureInvalid     ErrorCode = "signature_invalid"
+	CodeBusy                 ErrorCode = "busy"
+	CodeFeatureDisabled      ErrorCode = "feature_disabled"
+	CodeWebsiteBlocked       ErrorCode = "website_blocked"
+	CodeNavigationFailed     ErrorCode = "navigation_failed"
+	CodeNotAvailable         ErrorCode = "not_available"
+)
+
+func (ErrorCode) EnumValues() []string {
+	return []string{
+		string(CodeBadRequest),
+		string(CodeInternal),
+		string(CodePathInvalid),
+		string(CodeFileNotFound),
+		string(CodeNotFound),
+		string(CodeAlreadyExists),
+		string(CodeUnsupportedMediaType),
+		string(CodePreviewToolsMissing),
+		string(CodeUnauthorized),
+		string(CodeChecksumMismatch),
+		string(CodeSignatureInvalid),
+		string(CodeBusy),
+		string(CodeFeatureDisabled),
+		string(CodeWebsiteBlocked),
+		string(CodeNavigationFailed),
+		string(CodeNotAvailable),
+	}
 }
 
 var BadRequestDescription string = "Request uses invalid JSON syntax or does not follow request schema."